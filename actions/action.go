@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -23,6 +24,8 @@ type (
 		Status       mods.Result
 		Err          error
 		RequiredMods []mods.TrackedMod
+		// Plan is set by a DryRun
+		Plan *steps.Plan
 	}
 	action struct {
 		done             Done
@@ -37,9 +40,13 @@ const (
 	Install ActionKind = iota
 	Uninstall
 	Update
+	Repair
+	// DryRun builds a Plan of what installing a mod would do without changing anything
+	DryRun
 )
 
 var (
+	errRunning       = errors.New("Another action is running. Please wait for the current mod to finish installing or uninstalling.")
 	running          = false
 	mutex            = sync.Mutex{}
 	installMoveSteps = []steps.Step{
@@ -60,28 +67,44 @@ var (
 		steps.Uninstall,
 		steps.DisableMod,
 	}
+	repairMoveSteps = []steps.Step{
+		steps.PreDownload,
+		steps.ShowWorkingDialog,
+		steps.Download,
+		steps.Extract,
+		steps.Repair,
+		steps.PostInstall,
+	}
+	dryRunMoveSteps = []steps.Step{
+		steps.PreDownload,
+		steps.ShowWorkingDialog,
+		steps.Download,
+		steps.Extract,
+		steps.Conflicts,
+		steps.BuildPlan,
+	}
 	updateMoveSteps []steps.Step
 )
 
 func init() {
-	updateMoveSteps = make([]steps.Step, len(uninstallMoveSteps)+len(installMoveSteps)-1)
-	for i := 1; i < len(uninstallMoveSteps); i++ {
-		updateMoveSteps = append(updateMoveSteps, uninstallMoveSteps[i])
-	}
-	for _, s := range installMoveSteps {
-		updateMoveSteps = append(updateMoveSteps, s)
-	}
+	updateMoveSteps = make([]steps.Step, 0, len(uninstallMoveSteps)+len(installMoveSteps))
+	updateMoveSteps = append(updateMoveSteps, uninstallMoveSteps[1:]...)
+	updateMoveSteps = append(updateMoveSteps, steps.ApplyUpdate)
+	updateMoveSteps = append(updateMoveSteps, installMoveSteps...)
 }
 
-func init() {
-	updateMoveSteps = append(updateMoveSteps, installMoveSteps...)
+// Running reports whether an action is running.
+func Running() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return running
 }
 
 func New(kind ActionKind, game config.GameDef, mod mods.TrackedMod, done Done) (Action, error) {
 	mutex.Lock()
 	defer mutex.Unlock()
 	if running {
-		return nil, errors.New("Another action is running. Please wait for the current mod to finish installing or uninstalling.")
+		return nil, errRunning
 	}
 	a, err := new(kind, game, mod, done)
 	if err != nil {
@@ -103,10 +126,16 @@ func new(kind ActionKind, game config.GameDef, mod mods.TrackedMod, done Done) (
 		s, err = createUninstallSteps(game, mod)
 	case Update:
 		s, err = createUpdateSteps(game, mod)
+	case Repair:
+		s, err = createRepairSteps(game, mod)
+	case DryRun:
+		s, err = createDryRunSteps(game, mod)
 	}
+	state := steps.NewState(game, mod)
+	state.DryRun = kind == DryRun
 	return &action{
 		done:             done,
-		state:            steps.NewState(game, mod),
+		state:            state,
 		steps:            s,
 		isInternalAction: true,
 	}, err
@@ -142,11 +171,31 @@ func createUpdateSteps(game config.GameDef, tm mods.TrackedMod) (s []steps.Step,
 	return
 }
 
+func createRepairSteps(game config.GameDef, tm mods.TrackedMod) (s []steps.Step, err error) {
+	switch tm.InstallType(game) {
+	case config.Move, config.MoveToArchive:
+		s = repairMoveSteps
+	default:
+		err = fmt.Errorf("unknown install %s for mod %s", tm.InstallType(game), tm.Mod().Name)
+	}
+	return
+}
+
+func createDryRunSteps(game config.GameDef, tm mods.TrackedMod) (s []steps.Step, err error) {
+	switch tm.InstallType(game) {
+	case config.Move, config.MoveToArchive:
+		s = dryRunMoveSteps
+	default:
+		err = fmt.Errorf("unknown install %s for mod %s", tm.InstallType(game), tm.Mod().Name)
+	}
+	return
+}
+
 func (a action) Run() (err error) {
 	if !a.isInternalAction {
 		mutex.Lock()
 		if running {
-			err = errors.New("Another action is running. Please wait for the current mod to finish installing or uninstalling.")
+			err = errRunning
 		} else {
 			running = true
 		}
@@ -173,8 +222,15 @@ func (a action) run() {
 		result mods.Result
 		err    error
 	)
+	if !a.isInternalAction {
+		var cancel context.CancelFunc
+		a.state.Context, cancel = context.WithCancel(context.Background())
+		working.SetCancel(cancel)
+		defer cancel()
+	}
 	defer func() {
 		if !a.isInternalAction {
+			working.SetCancel(nil)
 			working.HideDialog()
 			mutex.Lock()
 			running = false
@@ -187,6 +243,10 @@ func (a action) run() {
 			}()
 		}
 	}()
+	result, err = a.runSteps()
+}
+
+func (a action) runSteps() (result mods.Result, err error) {
 	for i := 0; i < len(a.steps); i++ {
 		if result, err = a.steps[i](a.state); err != nil {
 			return
@@ -196,7 +256,11 @@ func (a action) run() {
 			working.ShowDialog()
 		} else if result == mods.Repeat {
 			i--
-			if a.state.Requires != nil {
+			if a.state.RequiresUpdate != nil {
+				if result, err = updateRequiredMod(a.state); result == mods.Cancel || result == mods.Error || err != nil {
+					return
+				}
+			} else if a.state.Requires != nil {
 				if result, err = installRequiredMod(a.state); result == mods.Cancel || result == mods.Error || err != nil {
 					return
 				}
@@ -209,6 +273,7 @@ func (a action) run() {
 			return
 		}
 	}
+	return
 }
 
 func installRequiredMod(state *steps.State) (result mods.Result, err error) {
@@ -216,7 +281,7 @@ func installRequiredMod(state *steps.State) (result mods.Result, err error) {
 	wg.Add(1)
 	go func(result *mods.Result, err *error) {
 		var (
-			a  Action
+			a  *action
 			tm mods.TrackedMod
 			e  error
 		)
@@ -238,6 +303,7 @@ func installRequiredMod(state *steps.State) (result mods.Result, err error) {
 			*err = e
 			return
 		}
+		a.state.Context = state.Context
 		if e = a.Run(); e != nil {
 			*result = mods.Error
 			*err = e
@@ -251,6 +317,22 @@ func installRequiredMod(state *steps.State) (result mods.Result, err error) {
 	return
 }
 
+func updateRequiredMod(state *steps.State) (mods.Result, error) {
+	tm := state.RequiresUpdate
+	state.RequiresUpdate = nil
+	a, err := new(Update, state.Game, tm, nil)
+	if err != nil {
+		return mods.Error, err
+	}
+	a.state.Context = state.Context
+	defer func() {
+		for _, d := range a.state.DirsToRemove {
+			_ = os.RemoveAll(d)
+		}
+	}()
+	return a.runSteps()
+}
+
 func (a action) newResult(r mods.Result, err error) Result {
 	if err != nil {
 		r = mods.Error
@@ -259,6 +341,7 @@ func (a action) newResult(r mods.Result, err error) Result {
 		Status:       r,
 		Err:          err,
 		RequiredMods: a.state.Added,
+		Plan:         a.state.Plan,
 	}
 }
 
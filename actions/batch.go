@@ -0,0 +1,156 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/snapshots"
+	"github.com/kiamev/moogle-mod-manager/ui/util/working"
+)
+
+type (
+	Job struct {
+		Kind ActionKind
+		Mod  mods.TrackedMod
+	}
+	batch struct {
+		game config.GameDef
+		jobs []Job
+		done Done
+		// snapshot names the automatic snapshot taken before anything else runs, none is taken when empty
+		snapshot string
+		// keepSnapshot is the id of a snapshot taking the automatic one must not prune
+		keepSnapshot string
+		// keepGoing runs the other mods' jobs after one fails instead of stopping, the failures are reported together
+		// once all have run
+		keepGoing bool
+		// start runs before the first job. Returning an error stops the batch.
+		start func() error
+		// finish runs after the last job and may replace the batch's error
+		finish func(result mods.Result, err error) error
+	}
+)
+
+// NewBatch creates an action that runs jobs one after another while holding the action lock.
+// The batch stops at the first job that does not succeed.
+func NewBatch(game config.GameDef, jobs []Job, done Done) (Action, error) {
+	return newBatch(game, jobs, done)
+}
+
+func newBatch(game config.GameDef, jobs []Job, done Done) (*batch, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if running {
+		return nil, errRunning
+	}
+	return &batch{
+		game: game,
+		jobs: jobs,
+		done: done,
+	}, nil
+}
+
+func (b *batch) Run() (err error) {
+	mutex.Lock()
+	if running {
+		err = errRunning
+	} else {
+		running = true
+	}
+	mutex.Unlock()
+	if err != nil {
+		return
+	}
+	go b.run()
+	return
+}
+
+func (b *batch) run() {
+	var (
+		result = mods.Ok
+		err    error
+		added  []mods.TrackedMod
+		failed = make(map[mods.ModID]string)
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	working.SetCancel(cancel)
+	defer cancel()
+	defer func() {
+		working.SetCancel(nil)
+		working.HideDialog()
+		mutex.Lock()
+		running = false
+		mutex.Unlock()
+		if b.done != nil {
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				if err != nil {
+					result = mods.Error
+				}
+				b.done(Result{
+					Status:       result,
+					Err:          err,
+					RequiredMods: added,
+				})
+			}()
+		}
+	}()
+	if b.snapshot != "" {
+		if _, err = snapshots.Take(b.game, b.snapshot, true, b.keepSnapshot); err != nil {
+			err = fmt.Errorf("failed to take a snapshot: %v", err)
+			return
+		}
+	}
+	if b.start != nil {
+		if err = b.start(); err != nil {
+			return
+		}
+	}
+	for _, j := range b.jobs {
+		if _, found := failed[j.Mod.ID()]; found {
+			continue
+		}
+		var a *action
+		if a, err = new(j.Kind, b.game, j.Mod, nil); err == nil {
+			a.state.Context = ctx
+			result, err = a.runSteps()
+			for _, d := range a.state.DirsToRemove {
+				_ = os.RemoveAll(d)
+			}
+			added = append(added, a.state.Added...)
+		}
+		if err == nil && result == mods.Ok {
+			continue
+		}
+		if !b.keepGoing || (err == nil && result == mods.Cancel) {
+			break
+		}
+		if err == nil {
+			err = errors.New("did not finish")
+		}
+		failed[j.Mod.ID()] = fmt.Sprintf("%s: %v", j.Mod.DisplayName(), err)
+		result, err = mods.Ok, nil
+	}
+	if len(failed) > 0 && err == nil {
+		result, err = mods.Error, failedError(failed)
+	}
+	if b.finish != nil {
+		err = b.finish(result, err)
+	}
+}
+
+func failedError(failed map[mods.ModID]string) error {
+	sl := make([]string, 0, len(failed))
+	for _, f := range failed {
+		sl = append(sl, f)
+	}
+	sort.Strings(sl)
+	return fmt.Errorf("not every mod succeeded:\n%s", strings.Join(sl, "\n"))
+}
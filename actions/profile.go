@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+)
+
+// NewProfileSwitch creates an action that makes the named profile's mods the only enabled mods.
+// Only mods that are not already installed the way the profile recorded them are uninstalled or installed.
+func NewProfileSwitch(game config.GameDef, name string, done Done) (Action, error) {
+	p, found := profiles.Get(game, name)
+	if !found {
+		return nil, fmt.Errorf("profile [%s] not found", name)
+	}
+	jobs, err := ProfileSwitchJobs(game, p)
+	if err != nil {
+		return nil, err
+	}
+	b, err := newBatch(game, jobs, done)
+	if err != nil {
+		return nil, err
+	}
+	b.snapshot = fmt.Sprintf("Before switching to profile %s", name)
+	// The prompter is process-wide. Swapping it for the whole batch only affects the batch's own steps as no other
+	// action can run until the batch is done, and finish puts the previous one back however the batch ends.
+	var previous prompt.Prompter
+	b.start = func() error {
+		previous = prompt.Get()
+		prompt.Set(prompt.NewReplay(p.Mods, p.Winners, previous))
+		return nil
+	}
+	b.finish = func(result mods.Result, err error) error {
+		prompt.Set(previous)
+		if err == nil && result == mods.Ok {
+			err = profiles.SetActive(game, name)
+		}
+		return err
+	}
+	return b, nil
+}
+
+// ProfileSwitchJobs returns the uninstalls, followed by the installs, needed to go from the enabled mods to p.
+func ProfileSwitchJobs(game config.GameDef, p *profiles.Profile) (jobs []Job, err error) {
+	var (
+		uninstall []mods.TrackedMod
+		install   []mods.TrackedMod
+		reinstall = make(map[mods.ModID]bool)
+	)
+	for id := range p.Mods {
+		tm, found := managed.TryGetMod(game, id)
+		if !found {
+			return nil, fmt.Errorf("mod [%s] in profile [%s] is no longer tracked", id, p.Name)
+		}
+		if !tm.Enabled() {
+			install = append(install, tm)
+		}
+	}
+	for _, tm := range managed.GetEnabledMods(game) {
+		choices, found := p.Mods[tm.ID()]
+		if !found {
+			uninstall = append(uninstall, tm)
+		} else if len(tm.Mod().Configurations) > 0 && !tm.Choices().Equals(choices) {
+			reinstall[tm.ID()] = true
+		}
+	}
+	// A mod that should win a conflict it currently loses is installed again so it can take the file back
+	for f, winner := range p.Winners {
+		if owner, found := files.HasFile(game, f); found && owner != winner {
+			if tm, ok := managed.TryGetMod(game, winner); ok && tm.Enabled() {
+				reinstall[winner] = true
+			}
+		}
+	}
+	for id := range reinstall {
+		tm, _ := managed.TryGetMod(game, id)
+		uninstall = append(uninstall, tm)
+		install = append(install, tm)
+	}
+
+	uninstall = mods.SortByDependencies(uninstall)
+	for i := len(uninstall) - 1; i >= 0; i-- {
+		jobs = append(jobs, Job{Kind: Uninstall, Mod: uninstall[i]})
+	}
+	for _, tm := range mods.SortByDependencies(install) {
+		jobs = append(jobs, Job{Kind: Install, Mod: tm})
+	}
+	return
+}
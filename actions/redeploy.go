@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"errors"
+
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+)
+
+// NewRedeploy creates an action that installs every enabled mod again after a game update. The changed files, the
+// game's new files that replaced the mods' files, first become the backups of the files the mods replace. Every mod is
+// then uninstalled and installed again from its cached download with the choices and conflict winners it had. A mod
+// that fails does not stop the others, the failures are reported once every mod has been redeployed.
+func NewRedeploy(game config.GameDef, changed []*files.Issue, done Done) (Action, error) {
+	var (
+		enabled = managed.GetEnabledMods(game)
+		choices = make(map[mods.ModID]mods.SelectedChoices)
+		winners = make(map[string]mods.ModID)
+	)
+	if len(enabled) == 0 {
+		return nil, errors.New("no mods are enabled")
+	}
+	for _, tm := range enabled {
+		choices[tm.ID()] = tm.Choices()
+		installed := files.Files(game, tm.ID())
+		for _, f := range installed.Keys() {
+			winners[f] = tm.ID()
+		}
+	}
+	b, err := newBatch(game, RedeployJobs(enabled), done)
+	if err != nil {
+		return nil, err
+	}
+	b.snapshot = "Before redeploying"
+	b.keepGoing = true
+	var previous prompt.Prompter
+	b.start = func() error {
+		if err := steps.RefreshBackups(game, changed); err != nil {
+			return err
+		}
+		previous = prompt.Get()
+		prompt.Set(prompt.NewReplay(choices, winners, previous))
+		return nil
+	}
+	b.finish = func(_ mods.Result, err error) error {
+		if previous != nil {
+			prompt.Set(previous)
+		}
+		return err
+	}
+	return b, nil
+}
+
+// RedeployJobs uninstalls the mods, those requiring others first, then installs them again in dependency order.
+func RedeployJobs(enabled []mods.TrackedMod) (jobs []Job) {
+	jobs = uninstallJobs(enabled)
+	for _, tm := range mods.SortByDependencies(enabled) {
+		jobs = append(jobs, Job{Kind: Install, Mod: tm})
+	}
+	return
+}
+
+// uninstallJobs uninstalls tms in reverse dependency order so no mod is uninstalled while another requires it.
+func uninstallJobs(tms []mods.TrackedMod) (jobs []Job) {
+	sorted := mods.SortByDependencies(tms)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		jobs = append(jobs, Job{Kind: Uninstall, Mod: sorted[i]})
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+)
+
+// NewRepair creates an action that puts back the missing and modified files found by files.Verify. Each mod is
+// extracted again from its cached download using the choices it was installed with.
+func NewRepair(game config.GameDef, issues []*files.Issue, done Done) (Action, error) {
+	var (
+		jobs    []Job
+		choices = make(map[mods.ModID]mods.SelectedChoices)
+	)
+	for _, i := range issues {
+		if !i.CanRepair() {
+			continue
+		}
+		if _, found := choices[i.Mod]; found {
+			continue
+		}
+		tm, found := managed.TryGetMod(game, i.Mod)
+		if !found {
+			return nil, fmt.Errorf("mod [%s] is no longer tracked", i.Mod)
+		}
+		choices[i.Mod] = tm.Choices()
+		jobs = append(jobs, Job{Kind: Repair, Mod: tm})
+	}
+	if len(jobs) == 0 {
+		return nil, errors.New("none of the issues can be repaired")
+	}
+	b, err := newBatch(game, jobs, done)
+	if err != nil {
+		return nil, err
+	}
+	var previous prompt.Prompter
+	b.start = func() error {
+		previous = prompt.Get()
+		prompt.Set(prompt.NewReplay(choices, nil, previous))
+		return nil
+	}
+	b.finish = func(_ mods.Result, err error) error {
+		prompt.Set(previous)
+		return err
+	}
+	return b, nil
+}
@@ -0,0 +1,25 @@
+package actions
+
+import (
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+)
+
+// NewRestoreVanilla creates an action that uninstalls every enabled mod, mods requiring others first, then restores
+// the backups that remain. Once done, files.CheckVanilla reports anything left over.
+func NewRestoreVanilla(game config.GameDef, done Done) (Action, error) {
+	b, err := newBatch(game, uninstallJobs(managed.GetEnabledMods(game)), done)
+	if err != nil {
+		return nil, err
+	}
+	b.snapshot = "Before restoring vanilla"
+	b.finish = func(result mods.Result, err error) error {
+		if err != nil || result != mods.Ok {
+			return err
+		}
+		return steps.RestoreBackups(game)
+	}
+	return b, nil
+}
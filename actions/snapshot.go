@@ -0,0 +1,34 @@
+package actions
+
+import (
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/snapshots"
+)
+
+// NewRestoreSnapshot creates an action that puts the game back the way s found it. A snapshot of the game is taken
+// first, then every enabled mod is uninstalled and the backups restored before the snapshot's files, file tracker
+// state and mods are put back.
+func NewRestoreSnapshot(game config.GameDef, s *snapshots.Snapshot, done Done) (Action, error) {
+	if err := s.Check(game); err != nil {
+		return nil, err
+	}
+	b, err := newBatch(game, uninstallJobs(managed.GetEnabledMods(game)), done)
+	if err != nil {
+		return nil, err
+	}
+	b.snapshot = "Before restoring snapshot " + s.ID
+	b.keepSnapshot = s.ID
+	b.finish = func(result mods.Result, err error) error {
+		if err != nil || result != mods.Ok {
+			return err
+		}
+		if err = steps.RestoreBackups(game); err != nil {
+			return err
+		}
+		return snapshots.Restore(game, s)
+	}
+	return b, nil
+}
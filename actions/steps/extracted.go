@@ -3,7 +3,9 @@ package steps
 import (
 	"fmt"
 	"io/fs"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/kiamev/moogle-mod-manager/archive"
@@ -26,35 +28,72 @@ type (
 	}
 )
 
-func newFileToInstallFromFile(relToExtracted map[string]archive.ExtractedFile, f *mods.ModFile, installDir string, archive *string) (*FileToInstall, error) {
-	af, found := relToExtracted[f.From]
-	if !found {
-		return nil, fmt.Errorf("file %v not found in extracted files", f)
+// newFileToInstallFromFile resolves f against the extracted files. When f.From is a pattern every file it matches is
+// installed, to f.To with the pattern's captures expanded, or into f.To when it names no single file.
+func newFileToInstallFromFile(relToExtracted map[string]archive.ExtractedFile, f *mods.ModFile, installDir string, archive *string) ([]*FileToInstall, error) {
+	if !mods.IsPattern(f.From) {
+		af, found := relToExtracted[f.From]
+		if !found {
+			return nil, fmt.Errorf("file %v not found in extracted files", f)
+		}
+		return []*FileToInstall{{
+			Relative:     f.To,
+			AbsoluteFrom: af.From,
+			AbsoluteTo:   filepath.Join(installDir, f.To),
+			Skip:         false,
+			archive:      archive,
+		}}, nil
 	}
-	return &FileToInstall{
-		Relative:     f.To,
-		AbsoluteFrom: af.From,
-		AbsoluteTo:   filepath.Join(installDir, f.To),
-		Skip:         false,
-		archive:      archive,
-	}, nil
+
+	p, err := mods.CompilePattern(f.From)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		matched []string
+		result  []*FileToInstall
+	)
+	for rel := range relToExtracted {
+		if p.Match(rel) {
+			matched = append(matched, rel)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("file pattern [%s] matched nothing in the extracted files", f.From)
+	}
+	sort.Strings(matched)
+	for _, rel := range matched {
+		to := p.Expand(f.To, rel)
+		if strings.HasSuffix(f.To, "/") || (len(matched) > 1 && to == f.To) {
+			to = path.Join(to, path.Base(rel))
+		}
+		result = append(result, &FileToInstall{
+			Relative:     to,
+			AbsoluteFrom: relToExtracted[rel].From,
+			AbsoluteTo:   filepath.Join(installDir, to),
+			Skip:         false,
+			archive:      archive,
+		})
+	}
+	return result, nil
 }
 
-func newFileToInstallFromDir(relToExtracted map[string]archive.ExtractedFile, rel string, d *mods.ModDir, installDir string, archive *string) (*FileToInstall, error) {
+// newFileToInstallFromDir installs the extracted file rel, which is within the directory from, under to.
+func newFileToInstallFromDir(relToExtracted map[string]archive.ExtractedFile, rel string, from string, to string, installDir string, archive *string) (*FileToInstall, error) {
 	var (
 		af, found = relToExtracted[rel]
 		toRel     = rel
 	)
 	if !found {
-		return nil, fmt.Errorf("dir %v not found in extracted files", d.From)
+		return nil, fmt.Errorf("dir %v not found in extracted files", from)
 	}
-	if d.From != "." {
-		toRel = strings.TrimPrefix(rel, d.From)
+	if from != "." {
+		toRel = strings.TrimPrefix(rel, from)
 	}
 	return &FileToInstall{
 		Relative:     af.Relative,
 		AbsoluteFrom: af.From,
-		AbsoluteTo:   filepath.Join(installDir, d.To, toRel),
+		AbsoluteTo:   filepath.Join(installDir, to, toRel),
 		Skip:         false,
 		archive:      archive,
 	}, nil
@@ -84,23 +123,19 @@ func (e *Extracted) Compile(game config.GameDef, extractedDir string) (err error
 	}
 	for _, df := range e.ToInstall.DownloadFiles {
 		for _, f := range df.Files {
-			if fti, err = newFileToInstallFromFile(fromToExtracted, f, installDir, f.ToArchive); err != nil {
+			var ftis []*FileToInstall
+			if ftis, err = newFileToInstallFromFile(fromToExtracted, f, installDir, f.ToArchive); err != nil {
 				return
 			}
-			e.filesToInstall = append(e.filesToInstall, fti)
-			/*ex, found := fromToExtracted[f.From]
-			if !found {
-				return nil, fmt.Errorf("file %v not found", f.From)
-			}
-			if f.From == f.To {
-				result = append(result, ex.Relative)
-			} else {
-				// Add root directory
-				filepath.Base(ex.File)
-				result = append(result, filepath.Join(f.To, filepath.Base(ex.File)))
-			}*/
+			e.filesToInstall = append(e.filesToInstall, ftis...)
 		}
 		for _, d := range df.Dirs {
+			if mods.IsPattern(d.From) {
+				if err = e.compileDirPattern(fromToExtracted, df, d, installDir); err != nil {
+					return
+				}
+				continue
+			}
 			if err = filepath.WalkDir(filepath.Join(extractedDir, d.From), func(path string, de fs.DirEntry, err error) error {
 				if err != nil {
 					return err
@@ -113,7 +148,10 @@ func (e *Extracted) Compile(game config.GameDef, extractedDir string) (err error
 					return err
 				}
 				rel = strings.ReplaceAll(rel, "\\", "/")
-				if fti, err = newFileToInstallFromDir(fromToExtracted, rel, d, installDir, d.ToArchive); err != nil {
+				if df.Skip(rel) {
+					return nil
+				}
+				if fti, err = newFileToInstallFromDir(fromToExtracted, rel, d.From, d.To, installDir, d.ToArchive); err != nil {
 					return err
 				}
 				e.filesToInstall = append(e.filesToInstall, fti)
@@ -125,3 +163,33 @@ func (e *Extracted) Compile(game config.GameDef, extractedDir string) (err error
 	}
 	return
 }
+
+// compileDirPattern installs the extracted files within the directories d's pattern matches.
+func (e *Extracted) compileDirPattern(fromToExtracted map[string]archive.ExtractedFile, df *mods.DownloadFiles, d *mods.ModDir, installDir string) error {
+	p, err := mods.CompilePattern(d.From)
+	if err != nil {
+		return err
+	}
+	rels := make([]string, 0, len(fromToExtracted))
+	for rel := range fromToExtracted {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	var matched bool
+	for _, rel := range rels {
+		dir, ok := p.MatchDir(rel, d.Recursive)
+		if !ok || df.Skip(rel) {
+			continue
+		}
+		matched = true
+		fti, err := newFileToInstallFromDir(fromToExtracted, rel, dir, p.Expand(d.To, dir), installDir, d.ToArchive)
+		if err != nil {
+			return err
+		}
+		e.filesToInstall = append(e.filesToInstall, fti)
+	}
+	if !matched {
+		return fmt.Errorf("dir pattern [%s] matched nothing in the extracted files", d.From)
+	}
+	return nil
+}
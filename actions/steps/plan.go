@@ -0,0 +1,200 @@
+package steps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+type (
+	// Plan is everything installing a mod would do, built by a dry run that changes nothing in the game.
+	Plan struct {
+		Game        config.GameID      `json:"game"`
+		Mod         mods.ModID         `json:"mod"`
+		Name        mods.ModName       `json:"name"`
+		Version     string             `json:"version"`
+		InstallType config.InstallType `json:"installType"`
+		Files       []*PlannedFile     `json:"files"`
+		Backups     []*PlannedBackup   `json:"backups"`
+		Archives    []*PlannedArchive  `json:"archives"`
+		Conflicts   []*PlannedConflict `json:"conflicts"`
+	}
+	PlannedFile struct {
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Archive string `json:"archive,omitempty"`
+		// Skip is set when another mod wins the file
+		Skip bool `json:"skip,omitempty"`
+	}
+	// PlannedBackup is a game file, or an archive's entry, that would be moved to the backup directory
+	PlannedBackup struct {
+		File    string `json:"file"`
+		Archive string `json:"archive,omitempty"`
+	}
+	PlannedArchive struct {
+		Archive  string   `json:"archive"`
+		Added    []string `json:"added"`
+		Replaced []string `json:"replaced"`
+	}
+	PlannedConflict struct {
+		Path  string     `json:"path"`
+		Owner mods.ModID `json:"owner"`
+		// Winner is empty when installing would ask which mod wins
+		Winner mods.ModID `json:"winner,omitempty"`
+	}
+)
+
+// BuildPlan records what the Install step would do with the extracted files in state.Plan.
+func BuildPlan(state *State) (mods.Result, error) {
+	var (
+		mod         = state.Mod.Mod()
+		installType = state.Mod.InstallType(state.Game)
+		installDir  string
+		backupDir   string
+		archives    = make(map[string]*PlannedArchive)
+		err         error
+		p           = &Plan{
+			Game:        state.Game.ID(),
+			Mod:         mod.ID(),
+			Name:        mod.Name,
+			Version:     mod.Version,
+			InstallType: installType,
+			// Empty rather than null for the tools reading the JSON
+			Files:     []*PlannedFile{},
+			Backups:   []*PlannedBackup{},
+			Archives:  []*PlannedArchive{},
+			Conflicts: []*PlannedConflict{},
+		}
+	)
+	if installDir, err = config.Get().GetDir(state.Game, config.GameDirKind); err != nil {
+		return mods.Error, err
+	}
+	if backupDir, err = config.Get().GetDir(state.Game, config.BackupDirKind); err != nil {
+		return mods.Error, err
+	}
+
+	for _, e := range state.ExtractedFiles {
+		for _, ti := range e.FilesToInstall() {
+			pf := &PlannedFile{
+				From: ti.AbsoluteFrom,
+				To:   ti.AbsoluteTo,
+				Skip: ti.Skip,
+			}
+			p.Files = append(p.Files, pf)
+			if installType == config.MoveToArchive && ti.archive != nil {
+				pf.Archive = *ti.archive
+				if err = planArchiveEntry(p, archives, installDir, ti); err != nil {
+					return mods.Error, err
+				}
+			} else if !ti.Skip {
+				if fi, e := os.Stat(ti.AbsoluteTo); e == nil && !fi.IsDir() {
+					if _, e = os.Stat(filepath.Join(backupDir, ti.Relative)); e != nil {
+						p.Backups = append(p.Backups, &PlannedBackup{File: ti.AbsoluteTo})
+					}
+				}
+			}
+		}
+	}
+	for _, a := range archives {
+		p.Archives = append(p.Archives, a)
+	}
+	sort.Slice(p.Archives, func(i, j int) bool { return p.Archives[i].Archive < p.Archives[j].Archive })
+
+	for _, c := range state.Conflicts {
+		pc := &PlannedConflict{
+			Path:  c.Path,
+			Owner: c.Owner.ID(),
+		}
+		if c.Selection != nil {
+			pc.Winner = c.Selection.ID()
+		}
+		p.Conflicts = append(p.Conflicts, pc)
+	}
+	state.Plan = p
+	return mods.Ok, nil
+}
+
+func planArchiveEntry(p *Plan, archives map[string]*PlannedArchive, installDir string, ti *FileToInstall) error {
+	rel, name, err := archiveEntry(installDir, ti)
+	if err != nil {
+		return err
+	}
+	var (
+		entry   = filepath.ToSlash(filepath.Join(rel, name))
+		absArch = filepath.Join(installDir, *ti.archive)
+		a, ok   = archives[*ti.archive]
+		found   bool
+	)
+	if !ok {
+		a = &PlannedArchive{Archive: *ti.archive, Added: []string{}, Replaced: []string{}}
+		archives[*ti.archive] = a
+	}
+	if _, err = os.Stat(absArch); err != nil {
+		return fmt.Errorf("archive not found: %s", absArch)
+	}
+	if found, err = archive.Contains(absArch, entry); err != nil {
+		return err
+	}
+	if found {
+		a.Replaced = append(a.Replaced, entry)
+		p.Backups = append(p.Backups, &PlannedBackup{File: entry, Archive: *ti.archive})
+	} else {
+		a.Added = append(a.Added, entry)
+	}
+	return nil
+}
+
+// JSON is the plan as indented JSON for reviewing tools.
+func (p *Plan) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+func (p *Plan) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s (%s), %s\n", p.Name, p.Version, p.Mod, p.InstallType))
+	sb.WriteString(fmt.Sprintf("\nFiles (%d):\n", len(p.Files)))
+	for _, f := range p.Files {
+		sb.WriteString("  " + f.To)
+		if f.Archive != "" {
+			sb.WriteString(" in " + f.Archive)
+		}
+		if f.Skip {
+			sb.WriteString(" (skipped, another mod wins)")
+		}
+		sb.WriteString("\n")
+	}
+	if len(p.Backups) > 0 {
+		sb.WriteString(fmt.Sprintf("\nBacked up (%d):\n", len(p.Backups)))
+		for _, b := range p.Backups {
+			if b.Archive != "" {
+				sb.WriteString(fmt.Sprintf("  %s in %s\n", b.File, b.Archive))
+			} else {
+				sb.WriteString(fmt.Sprintf("  %s\n", b.File))
+			}
+		}
+	}
+	if len(p.Archives) > 0 {
+		sb.WriteString(fmt.Sprintf("\nArchives modified (%d):\n", len(p.Archives)))
+		for _, a := range p.Archives {
+			sb.WriteString(fmt.Sprintf("  %s: %d added, %d replaced\n", a.Archive, len(a.Added), len(a.Replaced)))
+		}
+	}
+	if len(p.Conflicts) > 0 {
+		sb.WriteString(fmt.Sprintf("\nConflicts (%d):\n", len(p.Conflicts)))
+		for _, c := range p.Conflicts {
+			winner := "asked when installing"
+			if c.Winner != "" {
+				winner = string(c.Winner) + " wins"
+			}
+			sb.WriteString(fmt.Sprintf("  %s owned by %s, %s\n", c.Path, c.Owner, winner))
+		}
+	}
+	return sb.String()
+}
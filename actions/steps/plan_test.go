@@ -0,0 +1,125 @@
+package steps
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/loadorder"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+)
+
+func TestDryRun(t *testing.T) {
+	var (
+		game      = configtest.Game(t)
+		gameDir   = filepath.Join(config.PWD, "game")
+		extracted = filepath.Join(config.PWD, "extracted")
+		file      = filepath.Join(gameDir, "data.txt")
+	)
+	config.Get().GameDirs[string(game.ID())] = &config.GameDir{Dir: gameDir}
+	if err := managed.Initialize([]config.GameDef{game}); err != nil {
+		t.Fatal(err)
+	}
+	newMod := func(id mods.ModID) *mods.Mod {
+		return mods.NewMod(&mods.ModDef{ModID: id, Name: mods.ModName(id), Games: []*mods.Game{{ID: game.ID()}}})
+	}
+	if _, err := managed.AddMod(game, newMod("other")); err != nil {
+		t.Fatal(err)
+	}
+	// other's data.txt conflicts with the mod's, with nothing deciding which one wins
+	write(t, file, "other")
+	files.SetFiles(game, "other", file)
+	if err := loadorder.Set(game, []mods.ModID{"other"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := profiles.SetWinners(game, map[string]mods.ModID{"other.txt": "other"}); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(extracted, "data.txt"), "mod")
+	write(t, filepath.Join(extracted, "new.txt"), "new")
+
+	e := Extracted{
+		ToInstall: &mods.ToInstall{DownloadFiles: []*mods.DownloadFiles{{
+			Files: []*mods.ModFile{{From: "data.txt", To: "data.txt"}, {From: "new.txt", To: "new.txt"}},
+		}}},
+		Files: []archive.ExtractedFile{
+			{Name: "data.txt", From: filepath.Join(extracted, "data.txt"), Relative: "data.txt"},
+			{Name: "new.txt", From: filepath.Join(extracted, "new.txt"), Relative: "new.txt"},
+		},
+	}
+	if err := e.Compile(game, extracted); err != nil {
+		t.Fatal(err)
+	}
+	state := NewState(game, mods.NewTrackerMod(newMod("mod"), game))
+	state.DryRun = true
+	state.ExtractedFiles = []Extracted{e}
+
+	// Answering would let the mod win and remember it in the load order
+	previous := prompt.Get()
+	prompt.Set(prompt.NewAuto(prompt.AutoOptions{ReplaceConflicts: true, RememberConflicts: true}))
+	defer prompt.Set(previous)
+
+	before := tree(t, config.PWD)
+	for _, step := range []Step{Conflicts, BuildPlan} {
+		if r, err := step(state); err != nil || r != mods.Ok {
+			t.Fatalf("step result = %v, %v", r, err)
+		}
+	}
+	if after := tree(t, config.PWD); !reflect.DeepEqual(after, before) {
+		t.Errorf("dry run changed files\nbefore %v\nafter  %v", before, after)
+	}
+	if owner, _ := files.HasFile(game, file); owner != "other" {
+		t.Errorf("data.txt owner = %q, want %q", owner, "other")
+	}
+	if f := files.Files(game, "mod"); f.Len() > 0 {
+		t.Errorf("mod tracks %v", f.Keys())
+	}
+	if got := loadorder.Get(game); !reflect.DeepEqual(got, []mods.ModID{"other"}) {
+		t.Errorf("load order = %v, want [other]", got)
+	}
+
+	p := state.Plan
+	if len(p.Files) != 2 {
+		t.Errorf("%d planned files, want 2", len(p.Files))
+	}
+	if len(p.Backups) != 1 || p.Backups[0].File != file {
+		t.Errorf("planned backups = %v, want %s", p.Backups, file)
+	}
+	if len(p.Conflicts) != 1 || p.Conflicts[0].Owner != "other" || p.Conflicts[0].Winner != "" {
+		t.Errorf("planned conflicts = %v, want data.txt owned by other and asked when installing", p.Conflicts)
+	}
+}
+
+// tree is the content of every file under dir by path.
+func tree(t *testing.T, dir string) map[string]string {
+	m := make(map[string]string)
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		m[path] = string(b)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func write(t *testing.T, file, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
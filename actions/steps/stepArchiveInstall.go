@@ -6,43 +6,37 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"sync"
 
-	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/dialog"
-	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/archive"
 	"github.com/kiamev/moogle-mod-manager/config"
 	"github.com/kiamev/moogle-mod-manager/files"
 	"github.com/kiamev/moogle-mod-manager/mods"
-	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+	"github.com/kiamev/moogle-mod-manager/util"
 )
 
-const (
-	z7url = "https://www.7-zip.org/download.html"
-	z7cmd = "7z"
-)
+const z7url = "https://www.7-zip.org/download.html"
 
-func checkFor7zip() (mods.Result, error) {
-	if _, err := exec.Command("where", z7cmd).Output(); err != nil {
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		d := dialog.NewCustom(
-			"7-Zip not found",
-			"Ok",
-			container.NewCenter(widget.NewRichTextFromMarkdown(fmt.Sprintf(
-				"Please download 7-Zip from [%s](%s) and install it.\n\n"+
-					"Make sure to include it on the system path when installing.\n\n"+
-					"Restart Moogle Mod Manager once 7-zip is installed.", z7url, z7url),
-			)),
-			ui.ActiveWindow())
-		d.SetOnClosed(func() {
-			wg.Done()
-		})
-		d.Show()
-		wg.Wait()
-		return mods.Cancel, nil
+// checkFor7zip makes sure 7-Zip can be run when any of the archives is not a zip file. Zip files are changed
+// in-process, so 7-Zip is only needed for the other formats.
+func checkFor7zip(gameDir string, archives ...string) (mods.Result, error) {
+	var needed bool
+	for _, a := range archives {
+		if !archive.IsZip(filepath.Join(gameDir, a)) {
+			needed = true
+			break
+		}
 	}
-	return mods.Ok, nil
+	if !needed {
+		return mods.Ok, nil
+	}
+	for _, c := range archive.Z7Cmds {
+		if p, err := exec.LookPath(c); err == nil {
+			archive.Z7Cmd = p
+			return mods.Ok, nil
+		}
+	}
+	return prompt.Get().Missing7zip(z7url)
 }
 
 func installDirectMoveToArchive(state *State, backupDir string) (mods.Result, error) {
@@ -50,20 +44,26 @@ func installDirectMoveToArchive(state *State, backupDir string) (mods.Result, er
 		rel, name, bu string
 		absArch       string
 		installDir    string
-		b             []byte
 		ai            = newArchiveInjector()
-		r, err        = checkFor7zip()
-		dirsToRemove  []string
+		archives      []string
+		found         bool
+		r             mods.Result
+		err           error
 	)
-	if r != mods.Ok {
-		return r, err
-	}
 
 	if installDir, err = config.Get().GetDir(state.Game, config.GameDirKind); err != nil {
 		return mods.Error, err
 	} else if installDir == "" {
 		return mods.Error, fmt.Errorf("install directory not found")
 	}
+	for _, e := range state.ExtractedFiles {
+		for _, ti := range e.FilesToInstall() {
+			archives = append(archives, *ti.archive)
+		}
+	}
+	if r, err = checkFor7zip(installDir, archives...); r != mods.Ok {
+		return r, err
+	}
 
 	for _, e := range state.ExtractedFiles {
 		for _, ti := range e.FilesToInstall() {
@@ -71,36 +71,42 @@ func installDirectMoveToArchive(state *State, backupDir string) (mods.Result, er
 			if _, err = os.Stat(absArch); err != nil {
 				return mods.Error, fmt.Errorf("archive not found: %s", absArch)
 			}
-			if rel, err = filepath.Rel(installDir, ti.AbsoluteTo); err != nil {
+			if rel, name, err = archiveEntry(installDir, ti); err != nil {
 				return mods.Error, err
 			}
-			rel = filepath.Dir(rel)
-			name = filepath.Base(ti.Relative)
 			f := name
 			if rel != name && rel != "." && rel != "" {
 				f = fmt.Sprintf("%s/%s", rel, name)
 			}
-			// Check if file already exists in the zip file
-			cmd := exec.Command(z7cmd, "l", absArch, f)
-			b, err = cmd.Output()
-			if err == nil && !strings.Contains(string(b), "0 files") {
+			// Check if file already exists in the archive
+			if found, err = archive.Contains(absArch, f); err != nil {
+				return mods.Error, err
+			}
+			if found {
 				// Extract file and move to backup directory
 				if rel == name {
 					bu = filepath.Join(backupDir, archiveAsDir(ti.archive))
 				} else {
 					bu = filepath.Join(backupDir, archiveAsDir(ti.archive), rel)
 				}
-				if err = extractFile(absArch, rel, name, bu); err != nil {
-					return mods.Error, err
+				// An existing backup holds the vanilla file, the archive's entry may already be another mod's
+				if !util.FileExists(filepath.Join(bu, name)) {
+					if err = state.Journal.Extract(absArch, filepath.Join(bu, name)); err != nil {
+						return mods.Error, err
+					}
+					if err = extractFile(absArch, rel, name, bu); err != nil {
+						return mods.Error, err
+					}
+				}
+				if !files.HasBackup(state.Game, filepath.Join(bu, name)) {
+					if err = trackBackup(state.Game, filepath.Join(bu, name), filepath.Join(rel, name), *ti.archive); err != nil {
+						return mods.Error, err
+					}
 				}
 			}
-			if name == rel {
-				rel = "."
-			}
-			if dirsToRemove, err = ai.add(*ti.archive, ti.AbsoluteFrom, rel, name); err != nil {
+			if err = ai.inject(state, ti, rel, name); err != nil {
 				return mods.Error, err
 			}
-			state.DirsToRemove = append(state.DirsToRemove, dirsToRemove...)
 		}
 	}
 	if err = ai.updateArchives(state, installDir, archiveUpdate); err != nil {
@@ -109,6 +115,19 @@ func installDirectMoveToArchive(state *State, backupDir string) (mods.Result, er
 	return mods.Ok, nil
 }
 
+// archiveEntry returns the directory within the archive and the name ti is injected as.
+func archiveEntry(installDir string, ti *FileToInstall) (rel string, name string, err error) {
+	if rel, err = filepath.Rel(installDir, ti.AbsoluteTo); err != nil {
+		return
+	}
+	rel = filepath.Dir(rel)
+	name = filepath.Base(ti.Relative)
+	if name == rel {
+		rel = "."
+	}
+	return
+}
+
 func uninstallDirectMoveToArchive(state *State) (mods.Result, error) {
 	var (
 		absBackup    string
@@ -116,25 +135,32 @@ func uninstallDirectMoveToArchive(state *State) (mods.Result, error) {
 		backupDir    string
 		rel, name    string
 		ai           = newArchiveInjector()
-		r, err       = checkFor7zip()
+		archives     = files.Archives(state.Game, state.Mod.ID())
+		names        []string
+		r            mods.Result
+		err          error
 		dirsToRemove []string
 	)
-	if r != mods.Ok {
-		return r, err
-	}
 	if gameDir, err = config.Get().GetDir(state.Game, config.GameDirKind); err != nil {
 		return mods.Error, err
 	}
 	if backupDir, err = config.Get().GetDir(state.Game, config.BackupDirKind); err != nil {
 		return mods.Error, err
 	}
-	for a, i := range files.Archives(state.Game, state.Mod.ID()) {
+	for a := range archives {
+		names = append(names, a)
+	}
+	if r, err = checkFor7zip(gameDir, names...); r != mods.Ok {
+		return r, err
+	}
+	for a, i := range archives {
 		for _, f := range i.Keys() {
 			absBackup = filepath.Join(backupDir, archiveAsDir(&a), f)
 			rel = filepath.Dir(f)
 			name = filepath.Base(f)
 			if dirsToRemove, err = ai.add(a, absBackup, rel, name); err == nil {
 				state.DirsToRemove = append(state.DirsToRemove, dirsToRemove...)
+				files.RemoveBackups(state.Game, absBackup)
 			}
 			err = nil
 			// Ignore this error, in this case the file was not overridden
@@ -148,7 +174,7 @@ func uninstallDirectMoveToArchive(state *State) (mods.Result, error) {
 	return mods.Ok, nil
 }
 
-func extractFile(archive, rel, name string, backupDir string) error {
+func extractFile(absArch, rel, name string, backupDir string) error {
 	// Create the target directory
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
 		return err
@@ -159,11 +185,7 @@ func extractFile(archive, rel, name string, backupDir string) error {
 	if rel != name && rel != "." && rel != "" {
 		f = fmt.Sprintf("%s/%s", rel, name)
 	}
-	cmd := exec.Command(z7cmd, "e", archive, "-o"+backupDir, f)
-	if b, err := cmd.Output(); err != nil {
-		return fmt.Errorf("%s: %s", err, b)
-	}
-	return nil
+	return archive.ExtractEntry(absArch, f, filepath.Join(backupDir, name))
 }
 
 type (
@@ -172,6 +194,10 @@ type (
 	archiveFiles  struct {
 		dirToInject string
 		files       []string
+		// entries maps each injected file's path within the archive to where it was moved
+		entries map[string]string
+		// hashes of the injected files, keyed by their path within the archive
+		hashes map[string]string
 	}
 	archiveInjector struct {
 		archives map[archiveFile]*archiveFiles
@@ -195,9 +221,28 @@ func newArchiveInjector() *archiveInjector {
 	}
 }
 
-func (i *archiveInjector) add(archive, absoluteFrom string, rel, name string) (createdDirs []string, err error) {
-	archiveDirName := archiveAsDir(&archive)
-	af, ok := i.archives[archiveFile(archive)]
+// inject hashes ti and adds it to the files to inject into its archive.
+func (i *archiveInjector) inject(state *State, ti *FileToInstall, rel, name string) error {
+	h, err := util.HashFile(ti.AbsoluteFrom)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", ti.AbsoluteFrom, err)
+	}
+	dirsToRemove, err := i.add(*ti.archive, ti.AbsoluteFrom, rel, name)
+	if err != nil {
+		return err
+	}
+	state.DirsToRemove = append(state.DirsToRemove, dirsToRemove...)
+	af := i.archives[archiveFile(*ti.archive)]
+	if af.hashes == nil {
+		af.hashes = make(map[string]string)
+	}
+	af.hashes[filepath.Join(rel, name)] = h
+	return nil
+}
+
+func (i *archiveInjector) add(arch, absoluteFrom string, rel, name string) (createdDirs []string, err error) {
+	archiveDirName := archiveAsDir(&arch)
+	af, ok := i.archives[archiveFile(arch)]
 	rel = strings.ReplaceAll(rel, "\\", "/")
 	if !ok {
 		// Modify File Structure
@@ -209,7 +254,7 @@ func (i *archiveInjector) add(archive, absoluteFrom string, rel, name string) (c
 				return nil, fmt.Errorf("could not find [extracted] directory")
 			}
 		}
-		dir = filepath.Join(dir, asDir(archive))
+		dir = filepath.Join(dir, asDir(arch))
 		d := filepath.Join(dir, rel)
 		if err = os.MkdirAll(d, 0755); err != nil {
 			return
@@ -223,8 +268,8 @@ func (i *archiveInjector) add(archive, absoluteFrom string, rel, name string) (c
 				dir = filepath.Join(dir, rel)
 			}
 		}
-		af = &archiveFiles{dirToInject: dir}
-		i.archives[archiveFile(archive)] = af
+		af = &archiveFiles{dirToInject: dir, entries: make(map[string]string)}
+		i.archives[archiveFile(arch)] = af
 	}
 
 	// Move the file to its new relative location
@@ -241,25 +286,39 @@ func (i *archiveInjector) add(archive, absoluteFrom string, rel, name string) (c
 	}
 
 	af.files = append(af.files, filepath.Join(rel, name))
+	af.entries[filepath.ToSlash(filepath.Join(rel, name))] = to
 	return
 }
 
 func (i *archiveInjector) updateArchives(state *State, gameDir string, action archiveAction) (err error) {
-	// Update the zip file
-	var (
-		cmd *exec.Cmd
-		b   []byte
-	)
-	for archive, af := range i.archives {
-		cmd = exec.Command(z7cmd, "a", filepath.Join(gameDir, string(archive)), af.dirToInject, "-r", "-y")
-		if b, err = cmd.Output(); err != nil {
-			err = fmt.Errorf("%s: %s", err, b)
-			return
+	for arch, af := range i.archives {
+		absArch := filepath.Join(gameDir, string(arch))
+		if state.Journal != nil {
+			if err = state.Journal.Archive(absArch, string(arch), af.files); err != nil {
+				return
+			}
 		}
-		if action == archiveRestoreBackup {
-			files.RemoveArchiveFiles(state.Game, state.Mod.ID(), string(archive), af.files...)
+		if archive.IsZip(absArch) {
+			if err = archive.ZipUpdate(absArch, af.entries); err != nil {
+				err = fmt.Errorf("failed to update %s: %v", absArch, err)
+				return
+			}
 		} else {
-			files.AppendArchiveFiles(state.Game, state.Mod.ID(), string(archive), af.files...)
+			cmd := exec.Command(archive.Z7Cmd, "a", absArch, af.dirToInject, "-r", "-y")
+			var b []byte
+			if b, err = cmd.Output(); err != nil {
+				err = fmt.Errorf("%s: %s", err, b)
+				return
+			}
+		}
+		switch {
+		case state.Mod == nil:
+			// Backups restored on their own are not tracked by a mod
+		case action == archiveRestoreBackup:
+			files.RemoveArchiveFiles(state.Game, state.Mod.ID(), string(arch), af.files...)
+		default:
+			files.AppendArchiveFiles(state.Game, state.Mod.ID(), string(arch), af.files...)
+			files.SetArchiveHashes(state.Game, state.Mod.ID(), string(arch), af.hashes)
 		}
 		_ = os.RemoveAll(af.dirToInject)
 		_ = os.Remove(af.dirToInject)
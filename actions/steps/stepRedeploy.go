@@ -0,0 +1,63 @@
+package steps
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// ChangedGameFiles finds the mods' files that no longer match what was installed, which after a game update are the
+// game's new files written over them.
+func ChangedGameFiles(game config.GameDef) (changed []*files.Issue, err error) {
+	var issues []*files.Issue
+	if issues, err = files.VerifyAll(game); err != nil {
+		return
+	}
+	for _, i := range issues {
+		if i.Kind == files.Modified {
+			changed = append(changed, i)
+		}
+	}
+	return
+}
+
+// RefreshBackups makes the game's new files found by ChangedGameFiles the backups of the files they replaced, so
+// uninstalling the mods restores the updated game rather than the old one.
+func RefreshBackups(game config.GameDef, changed []*files.Issue) error {
+	var (
+		gameDir   string
+		backupDir string
+		absBackup string
+		rel       string
+		err       error
+	)
+	if gameDir, err = config.Get().GetDir(game, config.GameDirKind); err != nil {
+		return err
+	}
+	if backupDir, err = config.Get().GetDir(game, config.BackupDirKind); err != nil {
+		return err
+	}
+	for _, i := range changed {
+		if i.Archive == "" {
+			if rel, err = filepath.Rel(gameDir, i.File); err != nil {
+				return err
+			}
+			absBackup = filepath.Join(backupDir, rel)
+			if err = util.CopyFile(i.File, absBackup); err != nil {
+				return fmt.Errorf("failed to back up %s: %v", i.File, err)
+			}
+		} else {
+			absBackup = filepath.Join(backupDir, archiveAsDir(&i.Archive), i.File)
+			if err = extractFile(filepath.Join(gameDir, i.Archive), filepath.Dir(i.File), filepath.Base(i.File), filepath.Dir(absBackup)); err != nil {
+				return fmt.Errorf("failed to back up %s in %s: %v", i.File, i.Archive, err)
+			}
+		}
+		if err = trackBackup(game, absBackup, i.File, i.Archive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
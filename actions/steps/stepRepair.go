@@ -0,0 +1,87 @@
+package steps
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// Repair puts the mod's missing and modified files back from its extracted download. Backups cannot be repaired
+// this way as they are the game's own files.
+func Repair(state *State) (result mods.Result, err error) {
+	files.Batch(func() {
+		result, err = repair(state)
+	})
+	return
+}
+
+func repair(state *State) (mods.Result, error) {
+	var (
+		modID      = state.Mod.ID()
+		issues     []*files.Issue
+		toRepair   = make(map[string]bool)
+		installDir string
+		rel, name  string
+		ai         = newArchiveInjector()
+		err        error
+	)
+	if issues, err = files.Verify(state.Game, modID); err != nil {
+		return mods.Error, err
+	}
+	for _, i := range issues {
+		if i.CanRepair() {
+			toRepair[filepath.Join(i.Archive, i.File)] = true
+		}
+	}
+	if len(toRepair) == 0 {
+		return mods.Ok, nil
+	}
+	if installDir, err = config.Get().GetDir(state.Game, config.GameDirKind); err != nil {
+		return mods.Error, err
+	}
+
+	for _, e := range state.ExtractedFiles {
+		for _, ti := range e.FilesToInstall() {
+			if ti.archive == nil {
+				if !toRepair[ti.AbsoluteTo] {
+					continue
+				}
+				if err = util.MoveFile(ti.AbsoluteFrom, ti.AbsoluteTo); err != nil {
+					return mods.Error, fmt.Errorf("failed to repair %s: %v", ti.AbsoluteTo, err)
+				}
+				if err = trackHash(state.Game, modID, ti.AbsoluteTo); err != nil {
+					return mods.Error, err
+				}
+				continue
+			}
+
+			if rel, name, err = archiveEntry(installDir, ti); err != nil {
+				return mods.Error, err
+			}
+			if !toRepair[filepath.Join(*ti.archive, rel, name)] {
+				continue
+			}
+			if err = ai.inject(state, ti, rel, name); err != nil {
+				return mods.Error, err
+			}
+		}
+	}
+
+	if len(ai.archives) > 0 {
+		var archives []string
+		for a := range ai.archives {
+			archives = append(archives, string(a))
+		}
+		if r, err := checkFor7zip(installDir, archives...); r != mods.Ok {
+			return r, err
+		}
+		if err = ai.updateArchives(state, installDir, archiveUpdate); err != nil {
+			return mods.Error, err
+		}
+	}
+	return mods.Ok, nil
+}
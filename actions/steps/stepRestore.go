@@ -0,0 +1,100 @@
+package steps
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// RestoreBackups puts every backup the file tracker still holds back into the game, loose files and archive
+// entries alike, then removes the backup directory's empty directories. It finishes restoring the vanilla game once
+// every mod is uninstalled.
+func RestoreBackups(game config.GameDef) (err error) {
+	files.Batch(func() {
+		err = restoreBackups(game)
+	})
+	return
+}
+
+func restoreBackups(game config.GameDef) error {
+	var (
+		state     = NewState(game, nil)
+		ai        = newArchiveInjector()
+		gameDir   string
+		backupDir string
+		restored  []string
+		archives  []string
+		dirs      []string
+		err       error
+	)
+	defer func() {
+		for _, d := range state.DirsToRemove {
+			_ = os.RemoveAll(d)
+		}
+	}()
+	if gameDir, err = config.Get().GetDir(game, config.GameDirKind); err != nil {
+		return err
+	}
+	if backupDir, err = config.Get().GetDir(game, config.BackupDirKind); err != nil {
+		return err
+	}
+	for bu, b := range files.Backups(game) {
+		if b.Archive == "" {
+			if err = util.MoveFile(bu, b.File); err != nil {
+				return fmt.Errorf("failed to restore %s: %v", b.File, err)
+			}
+			files.RemoveBackups(game, bu)
+			continue
+		}
+		if dirs, err = ai.add(b.Archive, bu, filepath.Dir(b.File), filepath.Base(b.File)); err != nil {
+			ai.revertFileMoves()
+			return fmt.Errorf("failed to restore %s in %s: %v", b.File, b.Archive, err)
+		}
+		state.DirsToRemove = append(state.DirsToRemove, dirs...)
+		restored = append(restored, bu)
+	}
+
+	if len(ai.archives) > 0 {
+		for a := range ai.archives {
+			archives = append(archives, string(a))
+		}
+		if r, err := checkFor7zip(gameDir, archives...); r != mods.Ok {
+			ai.revertFileMoves()
+			if err == nil {
+				err = errors.New("7zip is required to restore the archives' backups")
+			}
+			return err
+		}
+		if err = ai.updateArchives(state, gameDir, archiveRestoreBackup); err != nil {
+			ai.revertFileMoves()
+			return err
+		}
+		files.RemoveBackups(game, restored...)
+	}
+	removeEmptyDirs(backupDir)
+	return nil
+}
+
+// removeEmptyDirs removes the directories under dir that hold no files, deepest first.
+func removeEmptyDirs(dir string) {
+	var found []string
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != dir {
+			found = append(found, path)
+		}
+		return nil
+	})
+	sort.Sort(sort.Reverse(sort.StringSlice(found)))
+	for _, d := range found {
+		// Only succeeds when the directory is empty
+		_ = os.Remove(d)
+	}
+}
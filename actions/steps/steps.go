@@ -1,12 +1,11 @@
 package steps
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
-	"time"
 
 	"github.com/kiamev/moogle-mod-manager/archive"
 	"github.com/kiamev/moogle-mod-manager/config"
@@ -14,13 +13,15 @@ import (
 	"github.com/kiamev/moogle-mod-manager/discover/repo"
 	"github.com/kiamev/moogle-mod-manager/downloads"
 	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/journal"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/mods/managed"
-	ci "github.com/kiamev/moogle-mod-manager/ui/config-installer"
-	"github.com/kiamev/moogle-mod-manager/ui/confirm"
-	uic "github.com/kiamev/moogle-mod-manager/ui/conflicts"
-	ui "github.com/kiamev/moogle-mod-manager/ui/state"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/conflictrules"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/loadorder"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+	"github.com/kiamev/moogle-mod-manager/prompt"
 	"github.com/kiamev/moogle-mod-manager/util"
+	ver "github.com/kiamev/moogle-mod-manager/version"
 )
 
 type (
@@ -30,17 +31,27 @@ type (
 		Downloaded     []string
 		ToInstall      []*mods.ToInstall
 		ExtractedFiles []Extracted
+		Choices        mods.SelectedChoices
 		Requires       *mods.Mod
+		RequiresUpdate mods.TrackedMod
+		Journal        *journal.Journal
 		Added          []mods.TrackedMod
 		DirsToRemove   []string
+		Conflicts      []*files.Conflict
+		// DryRun keeps the steps from changing anything so a Plan can be built
+		DryRun bool
+		Plan   *Plan
+		// Context is cancelled when the user cancels the action
+		Context context.Context
 	}
 	Step func(state *State) (result mods.Result, err error)
 )
 
 func NewState(game config.GameDef, mod mods.TrackedMod) *State {
 	return &State{
-		Game: game,
-		Mod:  mod,
+		Game:    game,
+		Mod:     mod,
+		Context: context.Background(),
 	}
 }
 
@@ -60,52 +71,169 @@ func VerifyEnable(state *State) (mods.Result, error) {
 		mod     *mods.Mod
 		found   bool
 		enabled bool
+		allowed bool
+		err     error
 	)
 	if c != nil {
 		if len(c.Forbids) > 0 {
 			for _, mc = range c.Forbids {
 				if t, found, enabled = managed.IsModEnabled(state.Game, mc.ModID()); found && enabled {
-					return mods.Error, fmt.Errorf("[%s] cannot be enabled because [%s] is enabled", tm.DisplayName(), t.DisplayName())
+					if allowed, err = mc.Allows(t.Mod().Version); err != nil {
+						return mods.Error, err
+					}
+					if allowed {
+						return mods.Error, fmt.Errorf("[%s] cannot be enabled because [%s] %s is enabled", tm.DisplayName(), t.DisplayName(), t.Mod().Version)
+					}
 				}
 			}
 		}
 		if len(c.Requires) > 0 {
 			for _, mc = range c.Requires {
 				t, found, enabled = managed.IsModEnabled(state.Game, mc.ModID())
-				if !enabled {
-					if t != nil {
-						mod = t.Mod()
-					} else {
-						l, err := discover.GetModsAsLookup(state.Game)
-						if err != nil {
+				if enabled {
+					if allowed, err = mc.Allows(t.Mod().Version); err != nil {
+						return mods.Error, err
+					}
+					if !allowed {
+						return requireUpdate(state, mc, t)
+					}
+					continue
+				}
+
+				if t != nil {
+					if allowed, err = mc.Allows(t.Mod().Version); err != nil {
+						return mods.Error, err
+					}
+					if !allowed {
+						var update *mods.Mod
+						if update, err = findRequiredUpdate(state.Game, mc, t); err != nil {
 							return mods.Error, err
 						}
-						if mod, found = l.GetByID(mc.ModID()); mod == nil || !found {
-							return mods.Error, fmt.Errorf("[%s] cannot be enabled because [%s] is not enabled", tm.DisplayName(), string(mc.ModID()))
+						if update == nil {
+							return mods.Error, fmt.Errorf("[%s] requires [%s] %s but only version %s is available", tm.DisplayName(), t.DisplayName(), versions(mc), t.Mod().Version)
 						}
-					}
-
-					var wg sync.WaitGroup
-					var result mods.Result
-					wg.Add(1)
-					confirm.ShowEnableModConfirmDialog(state.Mod.Mod().Name, mod.Mod(), func(r mods.Result) {
-						result = r
-						wg.Done()
-					})
-					wg.Wait()
-					if result == mods.Ok {
-						state.Requires = mod
+						if prompt.Get().UpdateRequiredMod(state.Mod.Mod().Name, t, update) != mods.Ok {
+							return mods.Cancel, nil
+						}
+						// Not installed so the newer definition can be used to enable it
+						t.SetUpdatedMod(update)
+						if err = managed.ApplyUpdate(t); err != nil {
+							return mods.Error, err
+						}
+						state.Requires = t.Mod()
 						return mods.Repeat, nil
-					} else {
-						return mods.Cancel, nil
+					}
+					mod = t.Mod()
+				} else {
+					l, err := discover.GetModsAsLookup(state.Game)
+					if err != nil {
+						return mods.Error, err
+					}
+					if mod, found = l.GetByID(mc.ModID()); mod == nil || !found {
+						return mods.Error, fmt.Errorf("[%s] cannot be enabled because [%s] is not enabled", tm.DisplayName(), string(mc.ModID()))
+					}
+					if allowed, err = mc.Allows(mod.Version); err != nil {
+						return mods.Error, err
+					}
+					if !allowed {
+						return mods.Error, fmt.Errorf("[%s] requires [%s] %s but only version %s is available", tm.DisplayName(), mod.Name, versions(mc), mod.Version)
 					}
 				}
+
+				if prompt.Get().EnableRequiredMod(state.Mod.Mod().Name, mod.Mod()) == mods.Ok {
+					state.Requires = mod
+					return mods.Repeat, nil
+				} else {
+					return mods.Cancel, nil
+				}
 			}
 		}
 	}
+	return verifyGameVersion(state)
+}
+
+// verifyGameVersion asks before enabling a mod that does not list the installed version of the game, or refuses to
+// when the configs say to block such mods.
+func verifyGameVersion(state *State) (mods.Result, error) {
+	mod := state.Mod.Mod()
+	supported := mod.GameVersions(state.Game)
+	if len(supported) == 0 {
+		return mods.Ok, nil
+	}
+	// A game not installed by Steam cannot be checked
+	v, manifest, err := config.InstalledVersion(state.Game)
+	if err != nil || manifest == nil {
+		return mods.Ok, nil
+	}
+	var installed config.VersionID
+	if v != nil {
+		if mod.SupportsVersion(state.Game, v.Version) {
+			return mods.Ok, nil
+		}
+		installed = v.Version
+	} else {
+		// A build no version describes, such as one released after the mod manager's game definition was updated
+		installed = config.VersionID(fmt.Sprintf("unknown build %d", manifest.BuildID))
+	}
+	if config.Get().BlockWrongGameVersion {
+		return mods.Error, fmt.Errorf("[%s] supports %s %v but %s is installed", state.Mod.DisplayName(), state.Game.Name(), supported, installed)
+	}
+	if prompt.Get().AllowGameVersion(mod, installed) != mods.Ok {
+		return mods.Cancel, nil
+	}
 	return mods.Ok, nil
 }
 
+// requireUpdate asks to update the enabled required mod t to a version mc allows.
+func requireUpdate(state *State, mc *mods.ModCompat, t mods.TrackedMod) (mods.Result, error) {
+	update, err := findRequiredUpdate(state.Game, mc, t)
+	if err != nil {
+		return mods.Error, err
+	}
+	if update == nil {
+		return mods.Error, fmt.Errorf("[%s] requires [%s] %s but version %s is enabled", state.Mod.DisplayName(), t.DisplayName(), versions(mc), t.Mod().Version)
+	}
+	if prompt.Get().UpdateRequiredMod(state.Mod.Mod().Name, t, update) != mods.Ok {
+		return mods.Cancel, nil
+	}
+	t.SetUpdatedMod(update)
+	state.RequiresUpdate = t
+	return mods.Repeat, nil
+}
+
+// findRequiredUpdate returns a newer definition of t that mc allows, nil if there is none.
+func findRequiredUpdate(game config.GameDef, mc *mods.ModCompat, t mods.TrackedMod) (*mods.Mod, error) {
+	var (
+		candidates = []*mods.Mod{t.UpdatedMod()}
+		allowed    bool
+		err        error
+	)
+	if l, e := discover.GetModsAsLookup(game); e == nil {
+		if m, found := l.GetByID(mc.ModID()); found {
+			candidates = append(candidates, m)
+		}
+	}
+	for _, m := range candidates {
+		if m == nil || ver.Equal(m.Version, t.Mod().Version) {
+			continue
+		}
+		if allowed, err = mc.Allows(m.Version); err != nil {
+			return nil, err
+		}
+		if allowed {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func versions(mc *mods.ModCompat) string {
+	if r, err := mc.VersionRange(); err == nil && !r.IsEmpty() {
+		return r.String()
+	}
+	return "any version"
+}
+
 func VerifyDisable(state *State) (mods.Result, error) {
 	var (
 		tm  = state.Mod
@@ -132,27 +260,22 @@ func VerifyDisable(state *State) (mods.Result, error) {
 
 func PreDownload(state *State) (result mods.Result, err error) {
 	var (
-		mod = state.Mod.Mod()
-		wg  sync.WaitGroup
+		mod     = state.Mod.Mod()
+		p       = prompt.Get()
+		choices []*mods.Choice
 	)
 	if len(mod.Configurations) > 0 {
 		// Handle any mod configurations
-		wg.Add(1)
 		modPath := filepath.Join(config.Get().GetModsFullPath(state.Game), mod.ID().AsDir())
-		if err = ui.GetScreen(ui.ConfigInstaller).(ci.ConfigInstaller).Setup(mod, modPath, func(r mods.Result, ti []*mods.ToInstall) error {
-			result = r
-			if r == mods.Ok && len(ti) > 0 {
-				state.ToInstall = append(state.ToInstall, ti...)
-			}
-			wg.Done()
-			return nil
-		}); err != nil {
-			// Failed to set up config installer screen
+		if result, choices, err = p.Configure(state.Game, mod, modPath); err != nil {
 			return mods.Error, err
 		}
-		ui.ShowScreen(ui.ConfigInstaller)
-		wg.Wait()
-		time.Sleep(100 * time.Millisecond)
+		if result == mods.Ok {
+			if state.ToInstall, err = mods.NewToInstallForMod(mod, mod.DownloadFilesForChoices(choices)); err != nil {
+				return mods.Error, err
+			}
+			state.Choices = mod.SelectedChoices(choices)
+		}
 	} else {
 		// No configurations, just handle the allways install
 		if state.ToInstall, err = mods.NewToInstallForMod(mod, mod.AlwaysDownload); err != nil {
@@ -167,24 +290,18 @@ func PreDownload(state *State) (result mods.Result, err error) {
 		return mods.Error, errors.New("no files to install")
 	}
 
-	wg.Add(1)
 	// Confirm Download
-	confirmer := confirm.NewConfirmer(confirm.NewParams(state.Game, state.Mod, state.ToInstall))
-	if err = confirmer.Downloads(func(r mods.Result) {
-		result = r
-		wg.Done()
-	}); err == nil {
-		wg.Wait()
-		time.Sleep(100 * time.Millisecond)
-	}
-	if err != nil {
+	if result, err = p.ConfirmDownloads(state.Game, state.Mod, state.ToInstall); err != nil {
 		return mods.Error, err
 	}
 	return result, nil
 }
 
 func Download(state *State) (result mods.Result, err error) {
-	if err = downloads.Download(state.Game, state.Mod, state.ToInstall); err != nil {
+	if err = downloads.Download(state.Context, state.Game, state.Mod, state.ToInstall); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return mods.Cancel, nil
+		}
 		result = mods.Error
 	} else {
 		result = mods.Ok
@@ -201,7 +318,10 @@ func Extract(state *State) (mods.Result, error) {
 	for _, ti := range state.ToInstall {
 		to = ti.Download.DownloadedArchiveLocation.ExtractDir(string(ti.Download.Name))
 
-		if ef, err = archive.Decompress(string(*ti.Download.DownloadedArchiveLocation), to, true, ti); err != nil {
+		if ef, err = archive.DecompressContext(state.Context, string(*ti.Download.DownloadedArchiveLocation), to, true, ti); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return mods.Cancel, nil
+			}
 			return mods.Error, err
 		}
 
@@ -223,7 +343,6 @@ func Conflicts(state *State) (result mods.Result, err error) {
 		conflicts      []*files.Conflict
 		tos            []string
 		tosToToInstall = make(map[string]*FileToInstall)
-		wg             sync.WaitGroup
 		ti             *FileToInstall
 		found          bool
 	)
@@ -254,26 +373,55 @@ func Conflicts(state *State) (result mods.Result, err error) {
 
 	result = mods.Ok
 	if len(conflicts) > 0 {
-		wg.Add(1)
-		uic.ShowConflicts(state.Mod.Mod(), conflicts, func(r mods.Result) {
-			result = r
-			if result == mods.Ok {
-				for _, c := range conflicts {
-					if c.Selection != mod {
-						// Use other mod
-						if ti, found = tosToToInstall[c.Path]; found {
-							ti.Skip = true
-						}
+		var unsettled []*files.Conflict
+		installDir, _ := config.Get().GetDir(state.Game, config.GameDirKind)
+		for _, c := range conflicts {
+			rel := c.Path
+			if r, e := filepath.Rel(installDir, c.Path); e == nil && installDir != "" {
+				rel = r
+			}
+			// The game's conflict rules come first, then the mods' order constraints and the load order
+			if w, decided := conflictrules.Winner(state.Game, rel, mod, c.Owner); decided {
+				c.Selection = w
+			} else if w, decided = loadorder.Winner(state.Game, mod, c.Owner); decided {
+				c.Selection = w
+			} else {
+				unsettled = append(unsettled, c)
+			}
+		}
+		if len(unsettled) > 0 && !state.DryRun {
+			var remember bool
+			if result, remember = prompt.Get().ResolveConflicts(mod, unsettled); result == mods.Ok && remember {
+				var won, lost []mods.ModID
+				for _, c := range unsettled {
+					if c.Selection == mod {
+						won = append(won, c.Owner.ID())
 					} else {
-						// Use this mod
-						files.RemoveFiles(state.Game, c.Owner.ID(), c.Path)
+						lost = append(lost, c.Owner.ID())
 					}
 				}
+				if err = loadorder.Place(state.Game, mod.ID(), won, lost); err != nil {
+					return mods.Error, fmt.Errorf("failed to save the load order: %v", err)
+				}
 			}
-			wg.Done()
-		})
-		wg.Wait()
+		}
+		if result == mods.Ok {
+			for _, c := range conflicts {
+				if c.Selection == nil && state.DryRun {
+					// Installing would ask
+					continue
+				}
+				// When this mod is used the other mod stops tracking the file as Install replaces it
+				if c.Selection != mod {
+					// Use other mod
+					if ti, found = tosToToInstall[c.Path]; found {
+						ti.Skip = true
+					}
+				}
+			}
+		}
 	}
+	state.Conflicts = conflicts
 	if err != nil {
 		return mods.Error, err
 	}
@@ -281,16 +429,123 @@ func Conflicts(state *State) (result mods.Result, err error) {
 }
 
 func Install(state *State) (result mods.Result, err error) {
+	files.Batch(func() {
+		result, err = installJournaled(state)
+	})
+	return
+}
+
+// installJournaled installs the mod's files with every change written to the journal first.
+func installJournaled(state *State) (result mods.Result, err error) {
 	var backupDir string
 	if backupDir, err = config.Get().GetDir(state.Game, config.BackupDirKind); err != nil {
 		return mods.Error, err
 	}
-	if result, err = install(state, backupDir); err != nil {
-		_, _ = Uninstall(state)
+	if state.Journal, err = journal.Begin(state.Game, state.Mod.ID()); err != nil {
+		return mods.Error, err
+	}
+	if err = untrackReplaced(state); err != nil {
+		result = mods.Error
+	} else {
+		result, err = install(state, backupDir)
+	}
+	if err != nil || result != mods.Ok {
+		if e := state.Journal.Rollback(); e != nil {
+			_, _ = Uninstall(state)
+		}
+		state.Journal = nil
+		return
+	}
+	if err = state.Journal.Installed(); err != nil {
+		return mods.Error, err
+	}
+	trackLost(state)
+	if err = trackWanted(state); err != nil {
+		return mods.Error, err
+	}
+	if err = trackWinners(state); err != nil {
+		return mods.Error, err
 	}
 	return
 }
 
+// untrackReplaced removes the files the mod won from the files tracked for the mods that installed them.
+func untrackReplaced(state *State) error {
+	mod := state.Mod.Mod()
+	for _, c := range state.Conflicts {
+		if owned := files.Files(state.Game, c.Owner.ID()); c.Selection != mod || !owned.Contains(c.Path) {
+			continue
+		}
+		if err := state.Journal.Untrack(c.Owner.ID(), c.Path); err != nil {
+			return err
+		}
+		files.RemoveFiles(state.Game, c.Owner.ID(), c.Path)
+	}
+	return nil
+}
+
+// trackLost records the files each side of the install's conflicts did not get, for the conflict overview.
+func trackLost(state *State) {
+	mod := state.Mod.Mod()
+	for _, c := range state.Conflicts {
+		if c.Selection == nil {
+			continue
+		}
+		if c.Selection == mod {
+			files.SetLost(state.Game, c.Owner.ID(), c.Path)
+		} else {
+			files.SetLost(state.Game, mod.ID(), c.Path)
+		}
+	}
+}
+
+// trackWinners records which mod won each of the install's conflicts for the active profile. It runs once the install
+// is marked installed so a rolled back install leaves the winners as they were.
+func trackWinners(state *State) error {
+	winners := make(map[string]mods.ModID)
+	for _, c := range state.Conflicts {
+		if c.Selection != nil {
+			winners[c.Path] = c.Selection.ID()
+		}
+	}
+	if len(winners) == 0 {
+		return nil
+	}
+	if err := profiles.SetWinners(state.Game, winners); err != nil {
+		return fmt.Errorf("failed to save the conflicts' winners: %v", err)
+	}
+	return nil
+}
+
+// trackWanted records every file and archive entry the install places, won or lost, so the conflict overview does not
+// have to extract the mod's downloads again.
+func trackWanted(state *State) error {
+	var (
+		wanted  []string
+		entries = make(map[string][]string)
+		gameDir string
+		err     error
+	)
+	if gameDir, err = config.Get().GetDir(state.Game, config.GameDirKind); err != nil {
+		return err
+	}
+	for _, e := range state.ExtractedFiles {
+		for _, ti := range e.FilesToInstall() {
+			if ti.archive == nil {
+				wanted = append(wanted, ti.AbsoluteTo)
+				continue
+			}
+			rel, name, err := archiveEntry(gameDir, ti)
+			if err != nil {
+				return err
+			}
+			entries[*ti.archive] = append(entries[*ti.archive], filepath.Join(rel, name))
+		}
+	}
+	files.SetWanted(state.Game, state.Mod.ID(), wanted, entries)
+	return nil
+}
+
 func install(state *State, backupDir string) (mods.Result, error) {
 	switch state.Mod.InstallType(state.Game) {
 	case config.Move:
@@ -318,7 +573,7 @@ func installDirectMove(state *State, backupDir string) (mods.Result, error) {
 				absBackup := filepath.Join(backupDir, ti.Relative)
 				if _, err = os.Stat(absBackup); err == nil {
 					// Backup Exists
-					if err = os.Remove(ti.AbsoluteTo); err != nil {
+					if err = state.Journal.Replace(ti.AbsoluteTo); err != nil {
 						return mods.Error, err
 					}
 				} else {
@@ -326,23 +581,69 @@ func installDirectMove(state *State, backupDir string) (mods.Result, error) {
 					if err = os.MkdirAll(filepath.Dir(absBackup), 0755); err != nil {
 						return mods.Error, err
 					}
+					if err = state.Journal.Backup(ti.AbsoluteTo, absBackup); err != nil {
+						return mods.Error, err
+					}
 					if err = util.MoveFile(ti.AbsoluteTo, absBackup); err != nil {
 						return mods.Error, err
 					}
 				}
+				if !files.HasBackup(state.Game, absBackup) {
+					if err = trackBackup(state.Game, absBackup, ti.AbsoluteTo, ""); err != nil {
+						return mods.Error, err
+					}
+				}
 			}
 
 			// Install the file
+			if err = state.Journal.Install(ti.AbsoluteFrom, ti.AbsoluteTo); err != nil {
+				return mods.Error, err
+			}
 			if err = util.MoveFile(ti.AbsoluteFrom, ti.AbsoluteTo); err != nil {
 				return mods.Error, err
 			}
 			files.SetFiles(state.Game, state.Mod.ID(), ti.AbsoluteTo)
+			if err = trackHash(state.Game, state.Mod.ID(), ti.AbsoluteTo); err != nil {
+				return mods.Error, err
+			}
 		}
 	}
 	return mods.Ok, nil
 }
 
-func Uninstall(state *State) (mods.Result, error) {
+func trackHash(game config.GameDef, modID mods.ModID, file string) error {
+	h, err := util.HashFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", file, err)
+	}
+	files.SetHash(game, modID, file, h)
+	return nil
+}
+
+func trackBackup(game config.GameDef, backup string, file string, archive string) error {
+	h, err := util.HashFile(backup)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", backup, err)
+	}
+	files.SetBackup(game, backup, file, archive, h)
+	return nil
+}
+
+func Uninstall(state *State) (result mods.Result, err error) {
+	files.Batch(func() {
+		result, err = uninstall(state)
+	})
+	if err == nil && result == mods.Ok {
+		if err = profiles.RemoveWinners(state.Game, state.Mod.ID()); err != nil {
+			result = mods.Error
+		}
+	}
+	return
+}
+
+func uninstall(state *State) (mods.Result, error) {
+	files.ClearLost(state.Game, state.Mod.ID())
+	files.ClearWanted(state.Game, state.Mod.ID())
 	switch state.Mod.InstallType(state.Game) {
 	case config.Move:
 		return uninstallMove(state)
@@ -382,6 +683,7 @@ func uninstallMove(state *State) (mods.Result, error) {
 			if err = util.MoveFile(absBackup, f); err != nil {
 				return mods.Error, err
 			}
+			files.RemoveBackups(state.Game, absBackup)
 		}
 	}
 	return mods.Ok, nil
@@ -389,9 +691,16 @@ func uninstallMove(state *State) (mods.Result, error) {
 
 func EnableMod(state *State) (result mods.Result, err error) {
 	result = mods.Ok
+	state.Mod.SetChoices(state.Choices)
 	if err = managed.EnableMod(state.Mod); err != nil {
 		result = mods.Error
+		if state.Journal != nil {
+			_ = state.Journal.Rollback()
+		}
+	} else if state.Journal != nil {
+		err = state.Journal.Commit()
 	}
+	state.Journal = nil
 	return
 }
 
@@ -403,6 +712,14 @@ func DisableMod(state *State) (result mods.Result, err error) {
 	return
 }
 
+func ApplyUpdate(state *State) (result mods.Result, err error) {
+	result = mods.Ok
+	if err = managed.ApplyUpdate(state.Mod); err != nil {
+		result = mods.Error
+	}
+	return
+}
+
 func ShowWorkingDialog(_ *State) (mods.Result, error) {
 	return mods.Working, nil
 }
@@ -2,6 +2,8 @@ package archive
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/gen2brain/go-unarr"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/mholt/archiver/v4"
@@ -25,10 +27,23 @@ type (
 		includeBaseDirRecursive bool
 		dirs                    map[string]bool
 		includeBaseDir          bool
+		downloadFiles           []*mods.DownloadFiles
+		filePatterns            []*mods.SourcePattern
+		dirPatterns             []dirPattern
+	}
+	dirPattern struct {
+		*mods.SourcePattern
+		recursive bool
 	}
 )
 
 func Decompress(from string, to string, continueIfExists bool, ti *mods.ToInstall) (extracted []ExtractedFile, err error) {
+	return DecompressContext(context.Background(), from, to, continueIfExists, ti)
+}
+
+// DecompressContext is Decompress that stops once ctx is cancelled. Rar and tar archives stop right away, other
+// archives once they are extracted.
+func DecompressContext(ctx context.Context, from string, to string, continueIfExists bool, ti *mods.ToInstall) (extracted []ExtractedFile, err error) {
 	var (
 		f  *os.File
 		fi os.FileInfo
@@ -44,12 +59,36 @@ func Decompress(from string, to string, continueIfExists bool, ti *mods.ToInstal
 		}
 	}
 
-	if filepath.Ext(from) == ".rar" {
+	var format archiver.Format
+	if format, err = identify(from); err != nil {
+		return
+	}
+	switch fm := format.(type) {
+	case archiver.Rar:
+		if f, err = os.Open(from); err != nil {
+			return
+		}
+		defer func() { _ = f.Close() }()
+		if err = os.MkdirAll(to, 0777); err != nil {
+			return
+		}
+		err = fm.Extract(ctx, f, nil, e.extractFile)
+	case archiver.Tar, archiver.CompressedArchive:
 		if f, err = os.Open(from); err != nil {
 			return
 		}
-		err = archiver.Rar{}.Extract(context.Background(), f, nil, e.extractRar)
-	} else { // zip/7z
+		defer func() { _ = f.Close() }()
+		if err = os.MkdirAll(to, 0777); err != nil {
+			return
+		}
+		err = fm.(archiver.Extractor).Extract(ctx, f, nil, e.extractFile)
+	case archiver.Decompressor:
+		// A single compressed file, such as a .zst that is not a tarball
+		if err = os.MkdirAll(to, 0777); err != nil {
+			return
+		}
+		err = e.decompressFile(ctx, fm, from)
+	default: // zip/7z
 		if a, err = unarr.NewArchive(from); err != nil {
 			return
 		}
@@ -58,24 +97,58 @@ func Decompress(from string, to string, continueIfExists bool, ti *mods.ToInstal
 		if err = os.MkdirAll(to, 0777); err != nil {
 			return
 		}
-		err = e.extractArchive(a)
+		if err = e.extractArchive(a); err == nil {
+			err = ctx.Err()
+		}
 	}
 	extracted = e.extracted
 	return
 }
 
+// identify finds the archive's format from its first bytes rather than its extension. Formats that are not
+// recognised, zip and 7z among them, are left to unarr and come back as nil.
+func identify(file string) (format archiver.Format, err error) {
+	var f *os.File
+	if f, err = os.Open(file); err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if format, _, err = archiver.Identify("", f); err != nil {
+		if errors.Is(err, archiver.ErrNoMatch) {
+			err = nil
+		}
+		return nil, err
+	}
+	if _, ok := format.(archiver.Zip); ok {
+		return nil, nil
+	}
+	return
+}
+
 func newExtractor(to string, ti *mods.ToInstall) *extractor {
 	e := &extractor{
-		to:    to,
-		files: make(map[string]bool),
-		dirs:  make(map[string]bool),
+		to:            to,
+		files:         make(map[string]bool),
+		dirs:          make(map[string]bool),
+		downloadFiles: ti.DownloadFiles,
 	}
 	for _, df := range ti.DownloadFiles {
 		for _, f := range df.Files {
-			e.files[strings.ReplaceAll(f.From, "\\", "/")] = true
+			if mods.IsPattern(f.From) {
+				// Invalid patterns are reported when the extracted files are compiled
+				if p, err := mods.CompilePattern(f.From); err == nil {
+					e.filePatterns = append(e.filePatterns, p)
+				}
+			} else {
+				e.files[strings.ReplaceAll(f.From, "\\", "/")] = true
+			}
 		}
 		for _, d := range df.Dirs {
-			if d.From == "." {
+			if mods.IsPattern(d.From) {
+				if p, err := mods.CompilePattern(d.From); err == nil {
+					e.dirPatterns = append(e.dirPatterns, dirPattern{SourcePattern: p, recursive: d.Recursive})
+				}
+			} else if d.From == "." {
 				if d.Recursive {
 					e.includeBaseDirRecursive = true
 					break
@@ -96,43 +169,115 @@ func newExtractor(to string, ti *mods.ToInstall) *extractor {
 	return e
 }
 
-func (e *extractor) extractRar(_ context.Context, f archiver.File) (err error) {
-	if !f.IsDir() {
-		var r io.ReadCloser
-		if r, err = f.Open(); err != nil {
-			return
-		}
-		defer func() { _ = r.Close() }()
-
-		if e.shouldSkip(f.NameInArchive) {
-			return nil
-		}
+// extractFile streams f from a rar, tar or compressed tar into the extraction directory, keeping its file mode.
+func (e *extractor) extractFile(ctx context.Context, f archiver.File) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if !f.Mode().IsRegular() || e.shouldSkip(f.NameInArchive) {
+		return nil
+	}
+	var fp string
+	if fp, err = e.target(f.NameInArchive); err != nil {
+		return
+	}
+	var r io.ReadCloser
+	if r, err = f.Open(); err != nil {
+		return
+	}
+	defer func() { _ = r.Close() }()
+	if err = e.write(ctx, fp, r, f.Mode()); err != nil {
+		return
+	}
+	e.add(fp, f.NameInArchive)
+	return
+}
 
-		fp := filepath.Join(e.to, f.NameInArchive)
-		if err = os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
-			return
-		}
-		buf := new(strings.Builder)
-		if _, err = io.Copy(buf, r); err != nil {
-			return
-		}
-		var file *os.File
-		if file, err = os.Create(fp); err != nil {
-			return
-		}
-		defer func() { _ = file.Close() }()
+// decompressFile writes the single file compressed in from into the extraction directory, named after from
+// without its compression extension.
+func (e *extractor) decompressFile(ctx context.Context, d archiver.Decompressor, from string) (err error) {
+	var (
+		name = strings.TrimSuffix(filepath.Base(from), filepath.Ext(from))
+		f    *os.File
+		r    io.ReadCloser
+	)
+	if e.shouldSkip(name) {
+		return nil
+	}
+	if f, err = os.Open(from); err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if r, err = d.OpenReader(f); err != nil {
+		return
+	}
+	defer func() { _ = r.Close() }()
+	fp := filepath.Join(e.to, name)
+	if err = e.write(ctx, fp, r, 0644); err != nil {
+		return
+	}
+	e.add(fp, name)
+	return
+}
 
-		_, err = file.WriteString(buf.String())
+// target is where the archive entry name is extracted to. Entries that would land outside the extraction
+// directory are refused.
+func (e *extractor) target(name string) (string, error) {
+	fp := filepath.Join(e.to, name)
+	if rel, err := filepath.Rel(e.to, fp); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s is outside of the archive", name)
+	}
+	return fp, nil
+}
 
-		e.extracted = append(e.extracted, ExtractedFile{
-			Name:     strings.ReplaceAll(filepath.Base(fp), "\\", "/"),
-			From:     strings.ReplaceAll(fp, "\\", "/"),
-			Relative: strings.ReplaceAll(f.NameInArchive, "\\", "/"),
-		})
+// write streams r to fp. The owner can always read and write the file so it can be moved and removed later.
+// A partly written file is removed.
+func (e *extractor) write(ctx context.Context, fp string, r io.Reader, mode os.FileMode) (err error) {
+	if err = os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return
+	}
+	var (
+		file *os.File
+		perm = mode.Perm()
+	)
+	if perm == 0 {
+		// Archives made on Windows usually have no permissions
+		perm = 0644
+	}
+	if file, err = os.OpenFile(fp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0600); err != nil {
+		return
+	}
+	_, err = io.Copy(file, &contextReader{ctx: ctx, r: r})
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(fp)
 	}
 	return
 }
 
+// contextReader stops reading once ctx is cancelled so large entries do not have to finish first.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func (e *extractor) add(fp string, nameInArchive string) {
+	e.extracted = append(e.extracted, ExtractedFile{
+		Name:     strings.ReplaceAll(filepath.Base(fp), "\\", "/"),
+		From:     strings.ReplaceAll(fp, "\\", "/"),
+		Relative: strings.ReplaceAll(nameInArchive, "\\", "/"),
+	})
+}
+
 func (e *extractor) extractArchive(a *unarr.Archive) (err error) {
 	var (
 		files []string
@@ -167,25 +312,21 @@ func (e *extractor) extractArchive(a *unarr.Archive) (err error) {
 	return
 }
 
+// shouldSkip reports whether path is left out of the extraction. Files listed by path are always extracted, the
+// include and exclude patterns only apply to the ones found through dirs and patterns.
 func (e *extractor) shouldSkip(path string) bool {
-	var (
-		lowerName = strings.ToLower(filepath.Base(path))
-		found     bool
-	)
-	if strings.HasPrefix(lowerName, "readme") ||
-		strings.HasPrefix(lowerName, "license") ||
-		strings.HasPrefix(lowerName, ".git") ||
-		strings.HasPrefix(lowerName, "__macosx") ||
-		strings.HasPrefix(lowerName, ".ds_store") {
-		return true
-	}
+	var found bool
 	path = strings.ReplaceAll(path, "\\", "/")
 
-	if e.includeBaseDirRecursive {
+	if _, found = e.files[path]; found {
 		return false
 	}
 
-	if _, found = e.files[path]; found {
+	if e.excluded(path) {
+		return true
+	}
+
+	if e.includeBaseDirRecursive {
 		return false
 	}
 
@@ -203,5 +344,28 @@ func (e *extractor) shouldSkip(path string) bool {
 			return false
 		}
 	}
+	for _, p := range e.filePatterns {
+		if p.Match(path) {
+			return false
+		}
+	}
+	for _, p := range e.dirPatterns {
+		if _, ok := p.MatchDir(path, p.recursive); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// excluded reports whether the include and exclude patterns of every DownloadFiles leave out path.
+func (e *extractor) excluded(path string) bool {
+	if len(e.downloadFiles) == 0 {
+		return (&mods.DownloadFiles{}).Skip(path)
+	}
+	for _, df := range e.downloadFiles {
+		if !df.Skip(path) {
+			return false
+		}
+	}
 	return true
 }
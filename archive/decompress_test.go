@@ -0,0 +1,269 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/mholt/archiver/v4"
+)
+
+// installAll extracts every file of an archive.
+var installAll = &mods.ToInstall{DownloadFiles: []*mods.DownloadFiles{{Dirs: []*mods.ModDir{{From: ".", Recursive: true}}}}}
+
+// tarball is a tar holding entries, keyed by name, in name order.
+func tarball(t *testing.T, entries map[string]string) []byte {
+	names := make([]string, 0, len(entries))
+	for n := range entries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var (
+		b bytes.Buffer
+		w = tar.NewWriter(&b)
+	)
+	for _, n := range names {
+		if err := w.WriteHeader(&tar.Header{Name: n, Mode: 0644, Size: int64(len(entries[n])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entries[n])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// compress is b compressed with c.
+func compress(t *testing.T, c archiver.Compressor, b []byte) []byte {
+	var out bytes.Buffer
+	w, err := c.OpenWriter(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func zipped(t *testing.T) []byte {
+	var (
+		b bytes.Buffer
+		w = zip.NewWriter(&b)
+	)
+	if _, err := w.Create("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestIdentify(t *testing.T) {
+	tb := tarball(t, map[string]string{"a.txt": "a"})
+	tests := []struct {
+		name    string
+		content []byte
+		want    archiver.Format
+	}{
+		{"tar", tb, archiver.Tar{}},
+		{"tar.gz", compress(t, archiver.Gz{}, tb), archiver.CompressedArchive{Compression: archiver.Gz{}, Archival: archiver.Tar{}}},
+		{"tar.xz", compress(t, archiver.Xz{}, tb), archiver.CompressedArchive{Compression: archiver.Xz{}, Archival: archiver.Tar{}}},
+		{"tar.zst", compress(t, archiver.Zstd{}, tb), archiver.CompressedArchive{Compression: archiver.Zstd{}, Archival: archiver.Tar{}}},
+		{"zst", compress(t, archiver.Zstd{}, []byte("not a tarball")), archiver.Zstd{}},
+		{"rar", []byte("Rar!\x1a\x07\x01\x00 rest of the archive"), archiver.Rar{}},
+		{"rar 1.5", []byte("Rar!\x1a\x07\x00 rest of the archive"), archiver.Rar{}},
+		{"zip", zipped(t), nil},
+		{"text", []byte("neither an archive nor compressed"), nil},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		// The extension never says what the archive is
+		file := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".bin")
+		if err := os.WriteFile(file, tt.content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := identify(file)
+		if err != nil {
+			t.Errorf("identify(%s) error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("identify(%s) = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecompressTar(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		from = filepath.Join(dir, "mod.tar.gz")
+		to   = filepath.Join(dir, "out")
+	)
+	// readme.txt is one of the DefaultExcludes
+	b := compress(t, archiver.Gz{}, tarball(t, map[string]string{"a.txt": "a", "dir/b.txt": "b", "readme.txt": "readme"}))
+	if err := os.WriteFile(from, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := Decompress(from, to, false, installAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string, len(extracted))
+	for _, e := range extracted {
+		c, err := os.ReadFile(e.From)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[e.Relative] = string(c)
+	}
+	if want := map[string]string{"a.txt": "a", "dir/b.txt": "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("extracted %v, want %v", got, want)
+	}
+}
+
+func TestDecompressListedFiles(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		from = filepath.Join(dir, "mod.tar")
+		to   = filepath.Join(dir, "out")
+	)
+	if err := os.WriteFile(from, tarball(t, map[string]string{"readme.txt": "readme", "license.txt": "license", "notes.txt": "notes"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ti := &mods.ToInstall{DownloadFiles: []*mods.DownloadFiles{{
+		// Listed by path they are extracted although DefaultExcludes and Exclude match them, listed by a pattern not
+		Files:   []*mods.ModFile{{From: "readme.txt"}, {From: "notes.txt"}, {From: "license*"}},
+		Exclude: []string{"notes*"},
+	}}}
+	extracted, err := Decompress(from, to, false, ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range extracted {
+		got = append(got, e.Relative)
+	}
+	sort.Strings(got)
+	if want := []string{"notes.txt", "readme.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("extracted %v, want %v", got, want)
+	}
+}
+
+func TestTarget(t *testing.T) {
+	e := &extractor{to: filepath.Join("mods", "mod")}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"a.txt", filepath.Join("mods", "mod", "a.txt"), false},
+		{"dir/../a.txt", filepath.Join("mods", "mod", "a.txt"), false},
+		{"..a.txt", filepath.Join("mods", "mod", "..a.txt"), false},
+		{"/a.txt", filepath.Join("mods", "mod", "a.txt"), false},
+		{"..", "", true},
+		{"../a.txt", "", true},
+		{"dir/../../a.txt", "", true},
+		{"../mod2/a.txt", "", true},
+	}
+	for _, tt := range tests {
+		got, err := e.target(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("target(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("target(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecompressTraversal(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		from = filepath.Join(dir, "mod.tar")
+		to   = filepath.Join(dir, "out")
+	)
+	if err := os.WriteFile(from, tarball(t, map[string]string{"../evil.txt": "evil"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decompress(from, to, false, installAll); err == nil {
+		t.Error("Decompress() extracted an entry outside of the extraction directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+		t.Error("evil.txt was written")
+	}
+}
+
+// cancelReader cancels the extraction once its first bytes are read.
+type cancelReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	defer r.cancel()
+	if len(p) > 4 {
+		p = p[:4]
+	}
+	return r.r.Read(p)
+}
+
+func TestWriteCancelled(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		fp          = filepath.Join(t.TempDir(), "a.txt")
+		e           = &extractor{}
+	)
+	defer cancel()
+	err := e.write(ctx, fp, &cancelReader{r: strings.NewReader("a file cancelled part way"), cancel: cancel}, 0644)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("write() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err = os.Stat(fp); !os.IsNotExist(err) {
+		t.Error("partly written file was not removed")
+	}
+}
+
+func TestDecompressCancelled(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"mod.tar", tarball(t, map[string]string{"a.txt": "a"})},
+		{"mod.tar.xz", compress(t, archiver.Xz{}, tarball(t, map[string]string{"a.txt": "a"}))},
+		{"mod.zst", compress(t, archiver.Zstd{}, []byte("a"))},
+		{"mod.zip", zipped(t)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tt := range tests {
+		var (
+			dir  = t.TempDir()
+			from = filepath.Join(dir, tt.name)
+		)
+		if err := os.WriteFile(from, tt.content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecompressContext(ctx, from, filepath.Join(dir, "out"), false, installAll); !errors.Is(err, context.Canceled) {
+			t.Errorf("DecompressContext(%s) error = %v, want %v", tt.name, err, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+var (
+	// Z7Cmds are the names 7-Zip's command line is installed as, 7zz and 7za being common on Linux
+	Z7Cmds = []string{"7z", "7zz", "7za"}
+	// Z7Cmd runs 7-Zip for the archives that are not zip files
+	Z7Cmd = Z7Cmds[0]
+)
+
+// Contains reports whether the archive has the entry name. Zip files are read in-process, 7-Zip lists the other
+// formats.
+func Contains(archive string, name string) (bool, error) {
+	if IsZip(archive) {
+		return ZipContains(archive, name)
+	}
+	b, err := exec.Command(Z7Cmd, "l", "-slt", archive, zipName(name)).Output()
+	if err != nil {
+		return false, fmt.Errorf("%s: %s", err, b)
+	}
+	return listed(string(b), name), nil
+}
+
+// listed reports whether 7-Zip's technical listing (l -slt) has the entry name. Entries follow the "----------" line
+// and each starts with its "Path = " line, the ones before it describe the archive itself.
+func listed(out string, name string) bool {
+	var (
+		entries bool
+		n       = zipName(name)
+	)
+	for _, l := range strings.Split(out, "\n") {
+		l = strings.TrimRight(l, "\r")
+		if l == "----------" {
+			entries = true
+		} else if entries && strings.HasPrefix(l, "Path = ") && zipName(strings.TrimPrefix(l, "Path = ")) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractEntry writes the archive's entry name to the file to. Zip files are read in-process, 7-Zip reads the other
+// formats.
+func ExtractEntry(archive string, name string, to string) (err error) {
+	if IsZip(archive) {
+		return ZipExtract(archive, name, to)
+	}
+	if err = os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return
+	}
+	var dir string
+	if dir, err = os.MkdirTemp(filepath.Dir(to), ".extract"); err != nil {
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if b, e := exec.Command(Z7Cmd, "e", archive, "-o"+dir, zipName(name), "-y").Output(); e != nil {
+		return fmt.Errorf("%s: %s", e, b)
+	}
+	return os.Rename(filepath.Join(dir, filepath.Base(filepath.FromSlash(name))), to)
+}
+
+// UpdateEntries is ZipUpdate for any archive, 7-Zip changes the ones that are not zip files.
+func UpdateEntries(archive string, files map[string]string) (err error) {
+	if IsZip(archive) {
+		return ZipUpdate(archive, files)
+	}
+	var dir string
+	if dir, err = os.MkdirTemp(filepath.Dir(archive), ".update"); err != nil {
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	for name, f := range files {
+		if err = util.CopyFile(f, filepath.Join(dir, filepath.FromSlash(zipName(name)))); err != nil {
+			return
+		}
+	}
+	if b, e := exec.Command(Z7Cmd, "a", archive, dir+"/.", "-r", "-y").Output(); e != nil {
+		return fmt.Errorf("%s: %s", e, b)
+	}
+	return nil
+}
+
+// DeleteEntries is ZipDelete for any archive, 7-Zip changes the ones that are not zip files.
+func DeleteEntries(archive string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if IsZip(archive) {
+		return ZipDelete(archive, names)
+	}
+	args := []string{"d", archive}
+	for _, n := range names {
+		args = append(args, zipName(n))
+	}
+	if b, err := exec.Command(Z7Cmd, append(args, "-y")...).Output(); err != nil {
+		return fmt.Errorf("%s: %s", err, b)
+	}
+	return nil
+}
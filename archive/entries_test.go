@@ -0,0 +1,39 @@
+package archive
+
+import "testing"
+
+func TestListed(t *testing.T) {
+	const out = "7-Zip 23.01 (x64) : Copyright (c) 1999-2023 Igor Pavlov\r\n" +
+		"\r\n" +
+		"Listing archive: data.7z\r\n" +
+		"\r\n" +
+		"--\r\n" +
+		"Path = data.7z\r\n" +
+		"Type = 7z\r\n" +
+		"\r\n" +
+		"----------\r\n" +
+		"Path = a\\replaced.txt\r\n" +
+		"Size = 10\r\n" +
+		"\r\n" +
+		"Path = 10 files.txt\r\n" +
+		"Size = 0\r\n"
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"a/replaced.txt", true},
+		{"a\\replaced.txt", true},
+		{"./10 files.txt", true},
+		{"data.7z", false},
+		{"replaced.txt", false},
+		{"0 files", false},
+	}
+	for _, tt := range tests {
+		if got := listed(out, tt.name); got != tt.want {
+			t.Errorf("listed(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if listed("Path = data.7z\n----------\n", "data.7z") {
+		t.Error("listed() found the archive itself")
+	}
+}
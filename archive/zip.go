@@ -0,0 +1,255 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var zipSignature = []byte("PK\x03\x04")
+
+// IsZip reports whether file is a zip archive, whatever its extension.
+func IsZip(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	b := make([]byte, len(zipSignature))
+	if _, err = io.ReadFull(f, b); err != nil {
+		return false
+	}
+	return bytes.Equal(b, zipSignature)
+}
+
+// ZipContains reports whether the zip archive has the entry name.
+func ZipContains(archive string, name string) (bool, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = r.Close() }()
+	return findZipEntry(&r.Reader, name) != nil, nil
+}
+
+// ZipExtract writes the zip archive's entry name to the file to.
+func ZipExtract(archive string, name string, to string) (err error) {
+	var (
+		r   *zip.ReadCloser
+		rc  io.ReadCloser
+		out *os.File
+	)
+	if r, err = zip.OpenReader(archive); err != nil {
+		return
+	}
+	defer func() { _ = r.Close() }()
+	f := findZipEntry(&r.Reader, name)
+	if f == nil {
+		return fmt.Errorf("%s not found in %s", name, archive)
+	}
+	if rc, err = f.Open(); err != nil {
+		return
+	}
+	defer func() { _ = rc.Close() }()
+	if err = os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return
+	}
+	if out, err = os.Create(to); err != nil {
+		return
+	}
+	if _, err = io.Copy(out, rc); err == nil {
+		err = out.Sync()
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(to)
+	}
+	return
+}
+
+// ZipUpdate replaces the entries of the zip archive named by the keys of files with the contents of the files they
+// map to, adding the entries that do not exist yet. The archive is rewritten to a temporary file that replaces it
+// once complete, so a failed update leaves the archive as it was.
+func ZipUpdate(archive string, files map[string]string) error {
+	return zipRewrite(archive, files, nil)
+}
+
+// ZipDelete removes the entries names from the zip archive, the ones it does not have are ignored. Like ZipUpdate
+// the archive is only replaced once it has been rewritten.
+func ZipDelete(archive string, names []string) error {
+	return zipRewrite(archive, nil, names)
+}
+
+// zipRewrite rewrites the zip archive with the entries in files replaced or added and those in remove left out.
+func zipRewrite(archive string, files map[string]string, remove []string) (err error) {
+	var (
+		r       *zip.ReadCloser
+		out     *os.File
+		tmp     = archive + ".tmp"
+		written = make(map[string]bool)
+		method  = zip.Deflate
+	)
+	if r, err = zip.OpenReader(archive); err != nil {
+		return
+	}
+	defer func() { _ = r.Close() }()
+	if out, err = os.Create(tmp); err != nil {
+		return
+	}
+	defer func() {
+		if out != nil {
+			_ = out.Close()
+		}
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	replacements := make(map[string]string, len(files))
+	for name, file := range files {
+		replacements[zipName(name)] = file
+	}
+	removed := make(map[string]bool, len(remove))
+	for _, name := range remove {
+		removed[zipName(name)] = true
+	}
+	if len(r.File) > 0 {
+		// New entries are stored the same way as the archive's existing ones
+		method = r.File[0].Method
+	}
+
+	w := zip.NewWriter(out)
+	for _, f := range r.File {
+		if removed[zipName(f.Name)] {
+			continue
+		}
+		file, replace := replacements[zipName(f.Name)]
+		if !replace {
+			if err = w.Copy(f); err != nil {
+				return
+			}
+			continue
+		}
+		// A fresh header so nothing of the old entry, like its extra fields or data descriptor flag, is carried over
+		if err = writeZipEntry(w, &zip.FileHeader{Name: f.Name, Method: f.Method}, file); err != nil {
+			return
+		}
+		written[zipName(f.Name)] = true
+	}
+	added := make([]string, 0, len(replacements))
+	for name := range replacements {
+		if !written[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		if err = writeZipEntry(w, &zip.FileHeader{Name: name, Method: method}, replacements[name]); err != nil {
+			return
+		}
+	}
+	if err = w.SetComment(r.Comment); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	if err = out.Sync(); err != nil {
+		return
+	}
+	if err = out.Close(); err != nil {
+		return
+	}
+	out = nil
+	_ = r.Close()
+	return os.Rename(tmp, archive)
+}
+
+// ZipCreate writes a new zip archive holding files, keyed by their name in the archive, in name order. The archive is
+// written next to where it belongs first so a failure does not leave a partial archive behind.
+func ZipCreate(archive string, files map[string]string) (err error) {
+	var (
+		out   *os.File
+		tmp   = archive + ".tmp"
+		names = make([]string, 0, len(files))
+	)
+	if out, err = os.Create(tmp); err != nil {
+		return
+	}
+	defer func() {
+		if out != nil {
+			_ = out.Close()
+		}
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := zip.NewWriter(out)
+	for _, name := range names {
+		if err = writeZipEntry(w, &zip.FileHeader{Name: zipName(name), Method: zip.Deflate}, files[name]); err != nil {
+			return
+		}
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	if err = out.Close(); err != nil {
+		return
+	}
+	out = nil
+	return os.Rename(tmp, archive)
+}
+
+func writeZipEntry(w *zip.Writer, h *zip.FileHeader, file string) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	var fi os.FileInfo
+	if fi, err = in.Stat(); err != nil {
+		return err
+	}
+	// Sizes and checksums are recalculated by the writer
+	h.CompressedSize64, h.UncompressedSize64, h.CRC32 = 0, 0, 0
+	h.Modified = time.Now()
+	h.SetMode(fi.Mode())
+	fw, err := w.CreateHeader(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, in)
+	return err
+}
+
+func findZipEntry(r *zip.Reader, name string) *zip.File {
+	name = zipName(name)
+	for _, f := range r.File {
+		if zipName(f.Name) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// zipName is name as stored in a zip archive, with forward slashes and no leading "./" or "/".
+func zipName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for strings.HasPrefix(name, "./") || strings.HasPrefix(name, "/") {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "./"), "/")
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// extra is an extra field create gives every entry, replaced entries should not keep it
+var extra = []byte{0xfe, 0xca, 0, 0}
+
+// create writes a zip archive holding entries, keyed by name, stored with method and with the comment "comment".
+func create(t *testing.T, arch string, method uint16, entries map[string]string) {
+	f, err := os.Create(arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	w := zip.NewWriter(f)
+	for name, content := range entries {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: method, Extra: extra})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.SetComment("comment"); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// read returns the contents of the zip archive's entries by name, failing if any of them does not pass its checksum.
+func read(t *testing.T, arch string) map[string]string {
+	r, err := zip.OpenReader(arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	if r.Comment != "comment" {
+		t.Errorf("comment = %q, want %q", r.Comment, "comment")
+	}
+	entries := make(map[string]string, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		entries[f.Name] = string(b)
+	}
+	return entries
+}
+
+func write(t *testing.T, file, content string) string {
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestZipUpdate(t *testing.T) {
+	for _, method := range []uint16{zip.Store, zip.Deflate} {
+		var (
+			dir  = t.TempDir()
+			arch = filepath.Join(dir, "data.zip")
+		)
+		create(t, arch, method, map[string]string{"a.txt": "a", "dir/b.txt": "b", "kept.txt": "kept"})
+		err := ZipUpdate(arch, map[string]string{
+			"a.txt":      write(t, filepath.Join(dir, "a"), "new a"),
+			"dir\\b.txt": write(t, filepath.Join(dir, "b"), "a longer b than before"),
+			"./new.txt":  write(t, filepath.Join(dir, "new"), "new"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{"a.txt": "new a", "dir/b.txt": "a longer b than before", "kept.txt": "kept", "new.txt": "new"}
+		if got := read(t, arch); !reflect.DeepEqual(got, want) {
+			t.Errorf("method %d: entries = %v, want %v", method, got, want)
+		}
+		r, err := zip.OpenReader(arch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range r.File {
+			if f.Method != method {
+				t.Errorf("method %d: %s stored with method %d", method, f.Name, f.Method)
+			}
+			if kept := bytes.Contains(f.Extra, extra); kept != (f.Name == "kept.txt") {
+				t.Errorf("method %d: %s has the original extra field = %v, want %v", method, f.Name, kept, !kept)
+			}
+		}
+		_ = r.Close()
+	}
+}
+
+func TestUpdateEntries(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		arch = filepath.Join(dir, "data.zip")
+	)
+	create(t, arch, zip.Deflate, map[string]string{"a.txt": "a"})
+	for _, content := range []string{"first", "second"} {
+		if err := UpdateEntries(arch, map[string]string{"a.txt": write(t, filepath.Join(dir, "a"), content)}); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(t, arch)["a.txt"]; got != content {
+			t.Errorf("a.txt = %q, want %q", got, content)
+		}
+	}
+	if _, err := os.Stat(arch + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary archive left behind")
+	}
+}
+
+func TestDeleteEntries(t *testing.T) {
+	arch := filepath.Join(t.TempDir(), "data.zip")
+	create(t, arch, zip.Deflate, map[string]string{"a.txt": "a", "dir/b.txt": "b", "kept.txt": "kept"})
+	if err := DeleteEntries(arch, []string{"a.txt", "dir\\b.txt", "missing.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"kept.txt": "kept"}
+	if got := read(t, arch); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if err := DeleteEntries(arch, nil); err != nil {
+		t.Fatal(err)
+	}
+}
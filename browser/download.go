@@ -2,20 +2,85 @@ package browser
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+)
+
+const (
+	partSuffix = ".part"
+	// maxAttempts is how many times Download tries a download that fails without receiving anything
+	maxAttempts = 5
+	// maxResumes is how many times DownloadWithProgress resumes a download whose connection dropped
+	maxResumes       = 20
+	progressInterval = 250 * time.Millisecond
 )
 
-func Download(url, toDir string) (string, error) {
+// Progress is called while a file downloads. total is -1 when the server does not send the file's size.
+type Progress func(name string, downloaded, total int64)
+
+var (
+	progress      Progress
+	progressMutex sync.Mutex
+)
+
+// SetProgress sets the callback every download reports its progress to.
+func SetProgress(p Progress) {
+	progressMutex.Lock()
+	progress = p
+	progressMutex.Unlock()
+}
+
+// ReportProgress passes a download's progress to the callback set with SetProgress.
+func ReportProgress(name string, downloaded, total int64) {
+	progressMutex.Lock()
+	p := progress
+	progressMutex.Unlock()
+	if p != nil {
+		p(name, downloaded, total)
+	}
+}
+
+type statusError struct {
+	url    string
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("failed to download the mod's source at %s: %s", e.url, http.StatusText(e.status))
+}
+
+// Download streams url into toDir. The file is written as a .part file first so an interrupted download,
+// including one from an earlier run, resumes where it stopped. Failed downloads are tried again a few times.
+func Download(url, toDir string) (f string, err error) {
+	for attempt := 1; ; attempt++ {
+		if f, err = DownloadWithProgress(context.Background(), url, toDir, ReportProgress); err == nil || attempt >= maxAttempts || !IsRetryable(err) {
+			return
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// DownloadWithProgress streams url into toDir like Download, reporting to p. It resumes up to maxResumes times as long
+// as each try gets further into the file, retrying a download that receives nothing is left to the caller. Cancelling
+// ctx stops the download, leaving the .part file to resume from later.
+func DownloadWithProgress(ctx context.Context, url, toDir string, p Progress) (string, error) {
 	var (
 		name, err = getName(url)
-		buf       *bytes.Buffer
-		out       *os.File
 		file      = path.Join(toDir, name)
+		received  int64
 	)
 	if err != nil {
 		return "", err
@@ -26,23 +91,204 @@ func Download(url, toDir string) (string, error) {
 		return file, nil
 	}
 
-	if buf, err = download(url); err != nil {
+	if err = os.MkdirAll(toDir, 0777); err != nil {
 		return "", err
 	}
 
-	if err = os.MkdirAll(toDir, 0777); err != nil {
-		return "", err
+	offset := partSize(file + partSuffix)
+	for resumes := 0; ; resumes++ {
+		if received, err = downloadPart(ctx, url, name, file+partSuffix, p); err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		size := partSize(file + partSuffix)
+		if received == 0 || size <= offset {
+			return "", err
+		}
+		if resumes >= maxResumes {
+			return "", fmt.Errorf("gave up on %s after resuming it %d times: %v", name, maxResumes, err)
+		}
+		// The connection dropped after getting further into the file, resume
+		offset = size
 	}
 
-	// Create the file
-	if out, err = os.Create(file); err != nil {
+	if err = os.Rename(file+partSuffix, file); err != nil {
 		return "", err
 	}
+	return file, nil
+}
+
+// partSize is how much of the download part holds, 0 when it does not exist.
+func partSize(part string) int64 {
+	if fi, err := os.Stat(part); err == nil {
+		return fi.Size()
+	}
+	return 0
+}
+
+// IsRetryable reports whether a failed download may succeed when tried again.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status == http.StatusTooManyRequests || se.status >= 500
+	}
+	return true
+}
+
+// downloadPart appends what part is missing of url to part and returns how many bytes were received.
+func downloadPart(ctx context.Context, url, name, part string, p Progress) (received int64, err error) {
+	var (
+		offset int64
+		flags  = os.O_CREATE | os.O_WRONLY
+		req    *http.Request
+		resp   *http.Response
+		out    *os.File
+		total  int64
+	)
+	if fi, e := os.Stat(part); e == nil {
+		offset = fi.Size()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if resp, err = client().Do(req); err != nil {
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The server does not support ranges or this is a new download
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusPartialContent:
+		if start, _, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || start != offset {
+			_ = os.Remove(part)
+			return 0, fmt.Errorf("unexpected range from %s, restarting the download", url)
+		}
+		flags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		if _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			// The part file already holds the whole file
+			return 0, nil
+		}
+		_ = os.Remove(part)
+		return 0, fmt.Errorf("partial download of %s no longer matches the server, restarting the download", name)
+	default:
+		return 0, &statusError{url: url, status: resp.StatusCode}
+	}
+
+	total = -1
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	if out, err = os.OpenFile(part, flags, 0644); err != nil {
+		return
+	}
 	defer func() { _ = out.Close() }()
 
-	// Write the body to file
-	_, err = io.Copy(out, buf)
-	return file, err
+	var (
+		stall   = config.Get().StallTimeout()
+		stalled atomic.Bool
+		timer   = time.AfterFunc(stall, func() {
+			stalled.Store(true)
+			cancel()
+		})
+		r = &progressReader{
+			r:        resp.Body,
+			name:     name,
+			read:     offset,
+			total:    total,
+			progress: p,
+			onRead: func() {
+				timer.Reset(stall)
+			},
+		}
+	)
+	defer timer.Stop()
+	r.report()
+	received, err = io.Copy(out, r)
+	r.report()
+	if err != nil {
+		if stalled.Load() {
+			err = fmt.Errorf("download of %s received nothing for %v", name, stall)
+		}
+		return
+	}
+	if total >= 0 && offset+received != total {
+		err = fmt.Errorf("download of %s ended after %d of %d bytes", name, offset+received, total)
+	}
+	return
+}
+
+func client() *http.Client {
+	timeout := config.Get().ConnectTimeout()
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	t.TLSHandshakeTimeout = timeout
+	t.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: t}
+}
+
+// parseContentRange reads "bytes start-end/size" and "bytes */size". size is -1 when unknown.
+func parseContentRange(s string) (start int64, size int64, ok bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "bytes ")
+	sp := strings.Split(s, "/")
+	if len(sp) != 2 {
+		return
+	}
+	size = -1
+	if sp[1] != "*" {
+		var err error
+		if size, err = strconv.ParseInt(sp[1], 10, 64); err != nil {
+			return
+		}
+	}
+	if sp[0] != "*" {
+		var err error
+		if start, err = strconv.ParseInt(strings.Split(sp[0], "-")[0], 10, 64); err != nil {
+			return
+		}
+	}
+	return start, size, true
+}
+
+type progressReader struct {
+	r        io.Reader
+	name     string
+	read     int64
+	total    int64
+	reported time.Time
+	progress Progress
+	onRead   func()
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	r.read += int64(n)
+	r.onRead()
+	if time.Since(r.reported) >= progressInterval {
+		r.report()
+	}
+	return
+}
+
+func (r *progressReader) report() {
+	r.reported = time.Now()
+	if r.progress != nil {
+		r.progress(r.name, r.read, r.total)
+	}
 }
 
 func DownloadAsString(url string) (string, error) {
@@ -63,16 +309,15 @@ func DownloadAsBytes(url string) ([]byte, error) {
 
 func download(url string) (buf *bytes.Buffer, err error) {
 	var resp *http.Response
-	if resp, err = http.Get(url); err != nil {
+	if resp, err = client().Get(url); err != nil {
 		return
 	}
-	defer func() { resp.Body.Close() }()
+	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != 200 {
-		err = fmt.Errorf("failed to download the mod's source at %s", url)
+		err = &statusError{url: url, status: resp.StatusCode}
 		return
 	}
-	defer func() { _ = resp.Body.Close() }()
 
 	buf = new(bytes.Buffer)
 	_, err = buf.ReadFrom(resp.Body)
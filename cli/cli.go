@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/browser"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/secrets"
+	"github.com/kiamev/moogle-mod-manager/discover/repo"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/journal"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/conflictrules"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/loadorder"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+)
+
+type (
+	session struct {
+		game config.GameDef
+		out  io.Writer
+	}
+	command struct {
+		usage       string
+		description string
+		needsGame   bool
+		run         func(s *session, args []string) error
+	}
+	choiceFlags map[string][]string
+)
+
+var (
+	commands     = make(map[string]*command)
+	errCancelled = errors.New("cancelled")
+)
+
+func register(name string, c *command) {
+	commands[name] = c
+}
+
+// Run executes the command line interface with args (without the program name) and returns the exit code.
+func Run(args []string, in io.Reader, out io.Writer, errOut io.Writer) int {
+	var (
+		fs       = flag.NewFlagSet("mmm", flag.ContinueOnError)
+		gameID   = fs.String("game", "", "id of the game to manage, defaults to the configured default game")
+		yes      = fs.Bool("yes", false, "never prompt, answer every question with the defaults and flags given")
+		replace  = fs.Bool("replace-conflicts", false, "with -yes, the mod being installed wins file conflicts")
+		remember = fs.Bool("remember-conflicts", false, "with -yes, add the outcome of file conflicts to the load order")
+		required = fs.Bool("install-required", true, "with -yes, install mods required by the mod being installed")
+		anyVer   = fs.Bool("allow-game-version", false, "with -yes, enable mods that do not list the installed game version")
+		choices  = make(choiceFlags)
+		c        *command
+		found    bool
+		s        = &session{out: out}
+		err      error
+	)
+	fs.Var(choices, "choice", "with -yes, `Configuration=Choice` to select when installing, may be repeated")
+	fs.SetOutput(errOut)
+	fs.Usage = func() { printUsage(fs, errOut) }
+	if err = fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		printUsage(fs, errOut)
+		return 2
+	}
+	if c, found = commands[fs.Arg(0)]; !found {
+		_, _ = fmt.Fprintf(errOut, "unknown command %s\n", fs.Arg(0))
+		printUsage(fs, errOut)
+		return 2
+	}
+
+	if *yes {
+		prompt.Set(prompt.NewAuto(prompt.AutoOptions{
+			InstallRequired:   *required,
+			ReplaceConflicts:  *replace,
+			RememberConflicts: *remember,
+			AnyGameVersion:    *anyVer,
+			Choices:           choices,
+		}))
+	} else {
+		prompt.Set(prompt.NewTerminal(in, out))
+	}
+	browser.SetProgress(newProgress(errOut))
+
+	if err = initialize(errOut); err != nil {
+		_, _ = fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if c.needsGame {
+		if s.game, err = findGame(*gameID); err != nil {
+			_, _ = fmt.Fprintln(errOut, err)
+			return 1
+		}
+		state.CurrentGame = s.game
+	}
+	if err = c.run(s, fs.Args()[1:]); err != nil {
+		_, _ = fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+func initialize(errOut io.Writer) (err error) {
+	secrets.Initialize()
+
+	if err = repo.Initialize(); err != nil {
+		return
+	}
+
+	configs := config.Get()
+	if err = configs.Initialize(); err != nil {
+		return
+	}
+
+	if err = repo.NewGetter(repo.Read).Pull(); err != nil {
+		// Keep going with the local copy of the repo so offline machines still work
+		_, _ = fmt.Fprintf(errOut, "warning: failed to update the mod repository: %v\n", err)
+	}
+
+	if err = config.Initialize(repo.Dirs(repo.Read)); err != nil {
+		return
+	}
+	if err = files.Initialize(); err != nil {
+		return
+	}
+	if err = managed.Initialize(config.GameDefs()); err != nil {
+		return
+	}
+	if err = profiles.Initialize(); err != nil {
+		return
+	}
+	if err = loadorder.Initialize(); err != nil {
+		return
+	}
+	if err = conflictrules.Initialize(); err != nil {
+		return
+	}
+	// Finish or undo any install that was interrupted before anything else touches the game files
+	if err = journal.Recover(); err != nil {
+		return
+	}
+	configs.InitializeGames(config.GameDefs())
+	return
+}
+
+func findGame(id string) (config.GameDef, error) {
+	if id == "" {
+		if id = config.Get().DefaultGame; id == "" {
+			return nil, fmt.Errorf("no game specified, use -game with one of: %s", strings.Join(config.GameIDs()[1:], ", "))
+		}
+	}
+	return config.GameDefFromID(config.GameID(id))
+}
+
+func printUsage(fs *flag.FlagSet, out io.Writer) {
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	_, _ = fmt.Fprintf(out, "Usage: mmm [flags] <command> [arguments]\n\nCommands:\n")
+	for _, n := range names {
+		c := commands[n]
+		_, _ = fmt.Fprintf(out, "  %-40s %s\n", strings.TrimSpace(n+" "+c.usage), c.description)
+	}
+	_, _ = fmt.Fprintf(out, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+func (c choiceFlags) String() string {
+	var sb strings.Builder
+	for k, v := range c {
+		for _, ch := range v {
+			if sb.Len() > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(k + "=" + ch)
+		}
+	}
+	return sb.String()
+}
+
+func (c choiceFlags) Set(s string) error {
+	sp := strings.SplitN(s, "=", 2)
+	if len(sp) != 2 || sp[0] == "" || sp[1] == "" {
+		return fmt.Errorf("choice must be in the form Configuration=Choice")
+	}
+	c[sp[0]] = append(c[sp[0]], sp[1])
+	return nil
+}
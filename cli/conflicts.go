@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/files"
+)
+
+func init() {
+	register("conflicts", &command{
+		description: "list every file and archive entry more than one mod installed or wanted, which mod won and which lost",
+		needsGame:   true,
+		run:         conflicts,
+	})
+}
+
+func conflicts(s *session, _ []string) error {
+	contested, err := files.Overview(s.game)
+	if err != nil {
+		return err
+	}
+	if len(contested) == 0 {
+		_, _ = fmt.Fprintln(s.out, "no conflicts")
+	}
+	for _, c := range contested {
+		_, _ = fmt.Fprintln(s.out, c)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/kiamev/moogle-mod-manager/launch"
+)
+
+func init() {
+	register("launch", &command{
+		usage:       "[-list] [profile]",
+		description: "start the game with the named launch profile, or the default one, -list shows the profiles",
+		needsGame:   true,
+		run:         launchGame,
+	})
+}
+
+func launchGame(s *session, args []string) (err error) {
+	if len(args) > 0 && (args[0] == "-list" || args[0] == "--list") {
+		def := launch.Default(s.game)
+		w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+		for _, p := range launch.Profiles(s.game) {
+			mark := ""
+			if p == def {
+				mark = "*"
+			}
+			_, _ = fmt.Fprintf(w, "%s%s\t%s\n", mark, p.Name, p.Kind)
+		}
+		return w.Flush()
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("launch takes a single profile name")
+	}
+	p := launch.Default(s.game)
+	if len(args) == 1 {
+		if p, err = launch.Find(s.game, args[0]); err != nil {
+			return
+		}
+	}
+	if err = launch.Launch(s.game, p); err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(s.out, "launched %s with %s\n", s.game.Name(), p.Name)
+	return nil
+}
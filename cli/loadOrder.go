@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/loadorder"
+)
+
+func init() {
+	register("load-order", &command{
+		usage:       "[set <modID>...]",
+		description: "show or set the mod priority list, mods listed later win file conflicts",
+		needsGame:   true,
+		run:         loadOrder,
+	})
+}
+
+func loadOrder(s *session, args []string) error {
+	if len(args) == 0 {
+		for i, id := range loadorder.Get(s.game) {
+			_, _ = fmt.Fprintf(s.out, "%d\t%s\n", i+1, id)
+		}
+		return nil
+	}
+	if args[0] != "set" {
+		return fmt.Errorf("unknown load-order sub-command %s", args[0])
+	}
+	if len(args) == 1 {
+		return errors.New("load-order set requires at least one mod id")
+	}
+	order := make([]mods.ModID, 0, len(args)-1)
+	for _, id := range args[1:] {
+		if _, found := managed.TryGetMod(s.game, mods.ModID(id)); !found {
+			return fmt.Errorf("mod %s not found", id)
+		}
+		order = append(order, mods.ModID(id))
+	}
+	return loadorder.Set(s.game, order)
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/snapshots"
+)
+
+func init() {
+	register("list", &command{
+		description: "list the mods added to the game",
+		needsGame:   true,
+		run:         list,
+	})
+	register("add", &command{
+		usage:       "<url|file>",
+		description: "add a mod from a mod.json/mod.xml file or url, or a Nexus/CurseForge mod url",
+		needsGame:   true,
+		run:         add,
+	})
+	register("install", &command{
+		usage:       "<modID>...",
+		description: "install and enable mods",
+		needsGame:   true,
+		run:         install,
+	})
+	register("uninstall", &command{
+		usage:       "<modID>...",
+		description: "uninstall and disable mods",
+		needsGame:   true,
+		run:         uninstall,
+	})
+	register("update", &command{
+		usage:       "-all | <modID>...",
+		description: "update mods that have a newer version",
+		needsGame:   true,
+		run:         update,
+	})
+	register("check-updates", &command{
+		description: "list mods that have a newer version",
+		needsGame:   true,
+		run:         checkUpdates,
+	})
+}
+
+func list(s *session, _ []string) error {
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ENABLED\tID\tNAME\tVERSION")
+	for _, tm := range mods.SortTracked(managed.GetMods(s.game)) {
+		_, _ = fmt.Fprintf(w, "%v\t%s\t%s\t%s\n", tm.Enabled(), tm.ID(), tm.Mod().Name, tm.Mod().Version)
+	}
+	return w.Flush()
+}
+
+func add(s *session, args []string) (err error) {
+	var tm mods.TrackedMod
+	if len(args) != 1 {
+		return errors.New("add requires a single url or file")
+	}
+	if _, err = os.Stat(args[0]); err == nil {
+		tm, err = managed.AddModFromFile(s.game, args[0])
+	} else if strings.HasPrefix(args[0], "http") {
+		tm, err = managed.AddModFromUrl(s.game, args[0])
+	} else {
+		err = fmt.Errorf("%s is neither a file nor a url", args[0])
+	}
+	if err == nil {
+		_, _ = fmt.Fprintf(s.out, "added %s (%s)\n", tm.Mod().Name, tm.ID())
+	}
+	return
+}
+
+func install(s *session, args []string) error {
+	tms, err := getMods(s, args)
+	if err != nil {
+		return err
+	}
+	for _, tm := range tms {
+		if tm.Enabled() {
+			_, _ = fmt.Fprintf(s.out, "%s is already installed\n", tm.ID())
+			continue
+		}
+		if err = runAction(actions.Install, s, tm); err != nil {
+			return fmt.Errorf("failed to install %s: %v", tm.ID(), err)
+		}
+		_, _ = fmt.Fprintf(s.out, "installed %s\n", tm.ID())
+	}
+	return nil
+}
+
+func uninstall(s *session, args []string) error {
+	tms, err := getMods(s, args)
+	if err != nil {
+		return err
+	}
+	for _, tm := range tms {
+		if !tm.Enabled() {
+			_, _ = fmt.Fprintf(s.out, "%s is not installed\n", tm.ID())
+			continue
+		}
+		if err = runAction(actions.Uninstall, s, tm); err != nil {
+			return fmt.Errorf("failed to uninstall %s: %v", tm.ID(), err)
+		}
+		_, _ = fmt.Fprintf(s.out, "uninstalled %s\n", tm.ID())
+	}
+	return nil
+}
+
+func update(s *session, args []string) (err error) {
+	var (
+		tms []mods.TrackedMod
+		all = len(args) == 1 && (args[0] == "-all" || args[0] == "--all")
+	)
+	if all {
+		tms = managed.GetMods(s.game)
+	} else if tms, err = getMods(s, args); err != nil {
+		return
+	}
+	if err = findUpdates(s); err != nil {
+		return
+	}
+	if all {
+		if err = snapshotBeforeUpdates(s, tms); err != nil {
+			return
+		}
+	}
+	for _, tm := range tms {
+		if tm.UpdatedMod() == nil {
+			continue
+		}
+		from, to := tm.Mod().Version, tm.UpdatedMod().Version
+		if tm.Enabled() {
+			err = runAction(actions.Update, s, tm)
+		} else {
+			err = managed.ApplyUpdate(tm)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %v", tm.ID(), err)
+		}
+		_, _ = fmt.Fprintf(s.out, "updated %s from %s to %s\n", tm.ID(), from, to)
+	}
+	return
+}
+
+// snapshotBeforeUpdates takes an automatic snapshot when an installed mod is about to be updated, so the updates can
+// be rolled back together.
+func snapshotBeforeUpdates(s *session, tms []mods.TrackedMod) error {
+	for _, tm := range tms {
+		if tm.Enabled() && tm.UpdatedMod() != nil {
+			snap, err := snapshots.Take(s.game, "Before updating all mods", true)
+			if err != nil {
+				return fmt.Errorf("failed to take a snapshot: %v", err)
+			}
+			_, _ = fmt.Fprintf(s.out, "took snapshot %s\n", snap.ID)
+			return nil
+		}
+	}
+	return nil
+}
+
+func checkUpdates(s *session, _ []string) error {
+	if err := findUpdates(s); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tVERSION\tNEW VERSION")
+	for _, tm := range mods.SortTracked(managed.GetMods(s.game)) {
+		if u := tm.UpdatedMod(); u != nil {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tm.ID(), tm.Mod().Name, tm.Mod().Version, u.Version)
+		}
+	}
+	return w.Flush()
+}
+
+func findUpdates(s *session) (err error) {
+	managed.CheckForUpdates(s.game, func(e error) {
+		err = e
+	})
+	if errors.Is(err, managed.ErrUnknownUpdates) {
+		_, _ = fmt.Fprintf(s.out, "warning: %v\n", err)
+		err = nil
+	}
+	return
+}
+
+func getMods(s *session, ids []string) (tms []mods.TrackedMod, err error) {
+	if len(ids) == 0 {
+		return nil, errors.New("at least one mod id is required")
+	}
+	for _, id := range ids {
+		tm, found := managed.TryGetMod(s.game, mods.ModID(id))
+		if !found {
+			return nil, fmt.Errorf("mod %s has not been added to %s", id, s.game.Name())
+		}
+		tms = append(tms, tm)
+	}
+	return
+}
+
+func runAction(kind actions.ActionKind, s *session, tm mods.TrackedMod) error {
+	return waitForAction(s, func(done actions.Done) (actions.Action, error) {
+		return actions.New(kind, s.game, tm, done)
+	})
+}
+
+// waitForAction runs the action created by newAction and blocks until it is done.
+func waitForAction(s *session, newAction func(done actions.Done) (actions.Action, error)) error {
+	var (
+		done   = make(chan actions.Result, 1)
+		a, err = newAction(func(r actions.Result) {
+			done <- r
+		})
+	)
+	if err != nil {
+		return err
+	}
+	if err = a.Run(); err != nil {
+		return err
+	}
+	r := <-done
+	for _, rm := range r.RequiredMods {
+		_, _ = fmt.Fprintf(s.out, "installed required mod %s\n", rm.ID())
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+	if r.Status == mods.Cancel {
+		return errCancelled
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/pack"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+func init() {
+	register("pack", &command{
+		usage:       "<folder> <archive.zip> [mod.json]",
+		description: "zip a folder laid out like the game's directory and write the mod's download and file mappings for it",
+		needsGame:   true,
+		run:         packMod,
+	})
+}
+
+func packMod(s *session, args []string) (err error) {
+	if len(args) < 2 || len(args) > 3 {
+		return errors.New("pack requires a folder, the archive to write and optionally the mod.json to update")
+	}
+	var (
+		dir, archive = args[0], args[1]
+		modFile      string
+		mod          = &mods.Mod{}
+		r            *pack.Result
+	)
+	if len(args) == 3 {
+		modFile = args[2]
+	}
+	if modFile != "" && util.FileExists(modFile) {
+		if err = mod.LoadFromFile(modFile); err != nil {
+			return fmt.Errorf("failed to load %s: %v", modFile, err)
+		}
+	} else {
+		name := filepath.Base(filepath.Clean(dir))
+		mod.ModDef = &mods.ModDef{
+			ModID:   mods.ModID(util.CreateFileName(name)),
+			Name:    mods.ModName(name),
+			Version: "1.0",
+			ModKind: mods.ModKind{Kinds: mods.Kinds{mods.HostedAt}},
+		}
+	}
+	if r, err = pack.Pack(s.game, mod, dir, archive); err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(s.out, "packed %d files into %s (%s, sha256 %s)\n", len(r.Files), r.Archive, util.FormatSize(r.Size), r.Sha256)
+	for _, d := range r.Mappings.Dirs {
+		_, _ = fmt.Fprintf(s.out, "  dir  %s -> %s\n", d.From, pack.Target(d.To, d.ToArchive))
+	}
+	for _, f := range r.Mappings.Files {
+		_, _ = fmt.Fprintf(s.out, "  file %s -> %s\n", f.From, pack.Target(f.To, f.ToArchive))
+	}
+	for _, f := range r.Included {
+		_, _ = fmt.Fprintf(s.out, "included %s, which is left out by default\n", f)
+	}
+
+	if modFile == "" {
+		var b []byte
+		if b, err = json.MarshalIndent(mod.ModDef, "", "\t"); err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(s.out, string(b))
+		return nil
+	}
+	if err = mod.Save(modFile); err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(s.out, "wrote %s\n", modFile)
+	if v := mod.Validate(); v != "" {
+		_, _ = fmt.Fprintf(s.out, "the mod still needs:\n%s", v)
+	}
+	return nil
+}
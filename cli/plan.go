@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+)
+
+func init() {
+	register("plan", &command{
+		usage:       "[-json] <modID>",
+		description: "show what installing a mod would change without changing anything, -json prints it for tools",
+		needsGame:   true,
+		run:         plan,
+	})
+}
+
+func plan(s *session, args []string) (err error) {
+	var (
+		asJSON bool
+		p      *steps.Plan
+		b      []byte
+	)
+	if len(args) > 0 && (args[0] == "-json" || args[0] == "--json") {
+		asJSON = true
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return errors.New("plan requires one mod id")
+	}
+	tms, err := getMods(s, args)
+	if err != nil {
+		return
+	}
+	if err = waitForAction(s, func(done actions.Done) (actions.Action, error) {
+		return actions.New(actions.DryRun, s.game, tms[0], func(r actions.Result) {
+			p = r.Plan
+			done(r)
+		})
+	}); err != nil {
+		return
+	}
+	if p == nil {
+		return errors.New("no plan was made")
+	}
+	if !asJSON {
+		_, _ = fmt.Fprint(s.out, p)
+		return
+	}
+	if b, err = p.JSON(); err != nil {
+		return
+	}
+	_, err = fmt.Fprintln(s.out, string(b))
+	return
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+)
+
+func init() {
+	register("profile", &command{
+		usage:       "list | show <name> | save <name> | switch <name> | delete <name>",
+		description: "manage named sets of enabled mods",
+		needsGame:   true,
+		run:         profile,
+	})
+}
+
+func profile(s *session, args []string) error {
+	if len(args) == 0 {
+		return errors.New("profile requires a sub-command")
+	}
+	if args[0] == "list" {
+		return listProfiles(s)
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("profile %s requires a single profile name", args[0])
+	}
+	name := args[1]
+	switch args[0] {
+	case "show":
+		return showProfile(s, name)
+	case "save":
+		if _, err := profiles.Save(s.game, name); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(s.out, "saved profile %s\n", name)
+	case "switch":
+		if err := switchProfile(s, name); err != nil {
+			return fmt.Errorf("failed to switch to %s: %v", name, err)
+		}
+		_, _ = fmt.Fprintf(s.out, "switched to profile %s\n", name)
+	case "delete":
+		if _, found := profiles.Get(s.game, name); !found {
+			return fmt.Errorf("profile [%s] not found", name)
+		}
+		if err := profiles.Delete(s.game, name); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(s.out, "deleted profile %s\n", name)
+	default:
+		return fmt.Errorf("unknown profile sub-command %s", args[0])
+	}
+	return nil
+}
+
+func listProfiles(s *session) error {
+	var (
+		w      = tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+		active = profiles.Active(s.game)
+	)
+	_, _ = fmt.Fprintln(w, "ACTIVE\tNAME\tMODS")
+	for _, n := range profiles.Names(s.game) {
+		p, _ := profiles.Get(s.game, n)
+		_, _ = fmt.Fprintf(w, "%v\t%s\t%d\n", n == active, n, len(p.Mods))
+	}
+	return w.Flush()
+}
+
+func showProfile(s *session, name string) error {
+	p, found := profiles.Get(s.game, name)
+	if !found {
+		return fmt.Errorf("profile [%s] not found", name)
+	}
+	ids := make([]string, 0, len(p.Mods))
+	for id := range p.Mods {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		_, _ = fmt.Fprintln(s.out, id)
+		for c, choices := range p.Mods[mods.ModID(id)] {
+			_, _ = fmt.Fprintf(s.out, "  %s = %v\n", c, choices)
+		}
+	}
+	return nil
+}
+
+func switchProfile(s *session, name string) error {
+	p, found := profiles.Get(s.game, name)
+	if !found {
+		return fmt.Errorf("profile [%s] not found", name)
+	}
+	jobs, err := actions.ProfileSwitchJobs(s.game, p)
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.Kind == actions.Install {
+			_, _ = fmt.Fprintf(s.out, "install %s\n", j.Mod.ID())
+		} else {
+			_, _ = fmt.Fprintf(s.out, "uninstall %s\n", j.Mod.ID())
+		}
+	}
+	return waitForAction(s, func(done actions.Done) (actions.Action, error) {
+		return actions.NewProfileSwitch(s.game, name, done)
+	})
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kiamev/moogle-mod-manager/browser"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// newProgress writes download progress to w, overwriting the same line until the download finishes.
+func newProgress(w io.Writer) browser.Progress {
+	var current string
+	return func(name string, downloaded, total int64) {
+		if current != "" && current != name {
+			_, _ = fmt.Fprintln(w)
+		}
+		current = name
+		if total <= 0 {
+			_, _ = fmt.Fprintf(w, "\rdownloading %s: %s", name, util.FormatSize(downloaded))
+			return
+		}
+		_, _ = fmt.Fprintf(w, "\rdownloading %s: %s of %s (%d%%)", name, util.FormatSize(downloaded), util.FormatSize(total), downloaded*100/total)
+		if downloaded >= total {
+			_, _ = fmt.Fprintln(w)
+			current = ""
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+	"github.com/kiamev/moogle-mod-manager/files"
+)
+
+func init() {
+	register("redeploy", &command{
+		description: "after a game update, back up the game's new files and install every enabled mod again",
+		needsGame:   true,
+		run:         redeploy,
+	})
+}
+
+func redeploy(s *session, _ []string) (err error) {
+	var changed []*files.Issue
+	if changed, err = steps.ChangedGameFiles(s.game); err != nil {
+		return
+	}
+	for _, i := range changed {
+		_, _ = fmt.Fprintf(s.out, "changed by the game: %s\n", i)
+	}
+	if err = waitForAction(s, func(done actions.Done) (actions.Action, error) {
+		return actions.NewRedeploy(s.game, changed, done)
+	}); err != nil {
+		return fmt.Errorf("failed to redeploy: %v", err)
+	}
+	_, _ = fmt.Fprintln(s.out, "redeployed the enabled mods")
+	return nil
+}
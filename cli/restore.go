@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/files"
+)
+
+func init() {
+	register("restore-vanilla", &command{
+		description: "uninstall every enabled mod, restore all backups and report anything left over",
+		needsGame:   true,
+		run:         restoreVanilla,
+	})
+}
+
+func restoreVanilla(s *session, _ []string) error {
+	err := waitForAction(s, func(done actions.Done) (actions.Action, error) {
+		return actions.NewRestoreVanilla(s.game, done)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(s.out, "failed to restore: %v\n", err)
+	}
+	l, e := files.CheckVanilla(s.game)
+	if e != nil {
+		return e
+	}
+	if l.Clean() {
+		_, _ = fmt.Fprintln(s.out, l)
+		return err
+	}
+	_, _ = fmt.Fprint(s.out, l)
+	return errors.New("the game is not vanilla")
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/snapshots"
+)
+
+func init() {
+	register("snapshot", &command{
+		usage:       "list | take [name] | restore <id> | rollback | delete <id>",
+		description: "save the state of the game's modded files and mods, and put it back",
+		needsGame:   true,
+		run:         snapshot,
+	})
+}
+
+func snapshot(s *session, args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("snapshot requires a sub-command")
+	}
+	var snap *snapshots.Snapshot
+	switch args[0] {
+	case "list":
+		return listSnapshots(s)
+	case "take":
+		name := strings.Join(args[1:], " ")
+		if name == "" {
+			name = "Snapshot"
+		}
+		if snap, err = snapshots.Take(s.game, name, false); err != nil {
+			return fmt.Errorf("failed to take a snapshot: %v", err)
+		}
+		_, _ = fmt.Fprintf(s.out, "took snapshot %s\n", snap.ID)
+		return nil
+	case "rollback":
+		if snap, err = snapshots.Latest(s.game); err != nil {
+			return
+		}
+		if snap == nil {
+			return errors.New("there are no snapshots to roll back to")
+		}
+		return restoreSnapshot(s, snap)
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("snapshot %s requires a single snapshot id", args[0])
+	}
+	switch args[0] {
+	case "restore":
+		if snap, err = snapshots.Get(s.game, args[1]); err != nil {
+			return
+		}
+		return restoreSnapshot(s, snap)
+	case "delete":
+		if err = snapshots.Delete(s.game, args[1]); err != nil {
+			return
+		}
+		_, _ = fmt.Fprintf(s.out, "deleted snapshot %s\n", args[1])
+		return nil
+	}
+	return fmt.Errorf("unknown snapshot sub-command %s", args[0])
+}
+
+func listSnapshots(s *session) error {
+	l, damaged, err := snapshots.List(s.game)
+	if err != nil {
+		return err
+	}
+	for _, snap := range l {
+		_, _ = fmt.Fprintln(s.out, snap)
+	}
+	for _, e := range damaged {
+		_, _ = fmt.Fprintf(s.out, "warning: %v\n", e)
+	}
+	return nil
+}
+
+func restoreSnapshot(s *session, snap *snapshots.Snapshot) error {
+	if err := waitForAction(s, func(done actions.Done) (actions.Action, error) {
+		return actions.NewRestoreSnapshot(s.game, snap, done)
+	}); err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %v", snap.ID, err)
+	}
+	_, _ = fmt.Fprintf(s.out, "restored snapshot %s\n", snap)
+	return nil
+}
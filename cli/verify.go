@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/files"
+)
+
+func init() {
+	register("verify", &command{
+		usage:       "[-repair] [modID...]",
+		description: "check that the installed files and their backups are unchanged, -repair puts back missing and modified files",
+		needsGame:   true,
+		run:         verify,
+	})
+}
+
+func verify(s *session, args []string) (err error) {
+	var (
+		repair bool
+		issues []*files.Issue
+	)
+	if len(args) > 0 && (args[0] == "-repair" || args[0] == "--repair") {
+		repair = true
+		args = args[1:]
+	}
+	if issues, err = verifyMods(s, args); err != nil {
+		return
+	}
+	printIssues(s, issues)
+
+	if repair && canRepair(issues) {
+		if err = waitForAction(s, func(done actions.Done) (actions.Action, error) {
+			return actions.NewRepair(s.game, issues, done)
+		}); err != nil {
+			return fmt.Errorf("failed to repair: %v", err)
+		}
+		if issues, err = verifyMods(s, args); err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(s.out, "after repair:")
+		printIssues(s, issues)
+	}
+
+	if len(issues) > 0 {
+		for _, i := range issues {
+			if !i.CanRepair() {
+				_, _ = fmt.Fprintln(s.out, "backups can only be restored by verifying the game's files with Steam")
+				break
+			}
+		}
+		if !repair && canRepair(issues) {
+			_, _ = fmt.Fprintln(s.out, "run verify -repair to put back the missing and modified files")
+		}
+		return fmt.Errorf("%d problem(s) found", len(issues))
+	}
+	return nil
+}
+
+func verifyMods(s *session, ids []string) (issues []*files.Issue, err error) {
+	if len(ids) == 0 {
+		return files.VerifyAll(s.game)
+	}
+	tms, err := getMods(s, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, tm := range tms {
+		var i []*files.Issue
+		if i, err = files.Verify(s.game, tm.ID()); err != nil {
+			return
+		}
+		issues = append(issues, i...)
+	}
+	return
+}
+
+func printIssues(s *session, issues []*files.Issue) {
+	if len(issues) == 0 {
+		_, _ = fmt.Fprintln(s.out, "no problems found")
+	}
+	for _, i := range issues {
+		_, _ = fmt.Fprintf(s.out, "%s\t%s\n", i.Mod, i)
+	}
+}
+
+func canRepair(issues []*files.Issue) bool {
+	for _, i := range issues {
+		if i.CanRepair() {
+			return true
+		}
+	}
+	return false
+}
@@ -6,12 +6,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"github.com/kiamev/moogle-mod-manager/util"
 )
 
-const configsFile = "configs.json"
+const (
+	configsFile = "configs.json"
+	// defaultDownloadTimeout and defaultDownloadStallTimeout are in seconds
+	defaultDownloadTimeout      = 30
+	defaultDownloadStallTimeout = 60
+)
 
 var (
 	PWD     string
@@ -24,8 +30,6 @@ const (
 	WindowWidth  = 1200
 	WindowHeight = 850
 
-	windowsRegLookup = "Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\Steam App "
-
 	// idChronoCross    = "1133760"
 	// TODO BoF
 )
@@ -62,6 +66,14 @@ type (
 		CheckForM3UpdateOnStart    *bool               `json:"checkAppUpdate"`
 		GameDirs                   map[string]*GameDir `json:"gameDirs"`
 		DeleteDownloadAfterInstall bool                `json:"deleteDownloadAfterInstall"`
+		// BlockWrongGameVersion refuses to enable mods that do not list the installed game version instead of asking
+		BlockWrongGameVersion bool `json:"blockWrongGameVersion"`
+		// DownloadTimeout is how many seconds to wait for a download server to connect and respond
+		DownloadTimeout int `json:"downloadTimeout"`
+		// DownloadStallTimeout is how many seconds a download may receive nothing before it is resumed
+		DownloadStallTimeout int `json:"downloadStallTimeout"`
+		// Launch holds how each game is launched, keyed by the game's ID
+		Launch map[string]*LaunchConfig `json:"launch,omitempty"`
 	}
 )
 
@@ -185,12 +197,35 @@ func (c *Configs) setDefaults() {
 	if c.BackupDir == "" {
 		c.BackupDir = filepath.Join(PWD, "backups")
 	}
+	if c.DownloadTimeout <= 0 {
+		c.DownloadTimeout = defaultDownloadTimeout
+	}
+	if c.DownloadStallTimeout <= 0 {
+		c.DownloadStallTimeout = defaultDownloadStallTimeout
+	}
+}
+
+// ConnectTimeout is DownloadTimeout as a duration, its default when it is not above zero.
+func (c *Configs) ConnectTimeout() time.Duration {
+	return seconds(c.DownloadTimeout, defaultDownloadTimeout)
+}
+
+// StallTimeout is DownloadStallTimeout as a duration, its default when it is not above zero.
+func (c *Configs) StallTimeout() time.Duration {
+	return seconds(c.DownloadStallTimeout, defaultDownloadStallTimeout)
+}
+
+func seconds(s int, def int) time.Duration {
+	if s <= 0 {
+		s = def
+	}
+	return time.Duration(s) * time.Second
 }
 
 func (c *Configs) InitializeGames(games []GameDef) {
 	for _, g := range games {
 		if i := c.GameDirs[string(g.ID())]; i == nil || i.Dir == "" {
-			if s := g.SteamDirFromRegistry(); s != "" {
+			if s := g.SteamDir(); s != "" {
 				c.GameDirs[string(g.ID())] = &GameDir{Dir: s}
 			}
 		}
@@ -0,0 +1,32 @@
+// Package configtest sets up the configuration packages need in their tests.
+package configtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+)
+
+// Game points config.PWD at a temporary directory and initializes a game with the ID "test" defined inside it.
+func Game(t testing.TB) config.GameDef {
+	t.Helper()
+	dir := t.TempDir()
+	config.PWD = dir
+	gameDir := filepath.Join(dir, "games", "test")
+	if err := os.MkdirAll(gameDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gameDir, "game.json"), []byte(`{"id":"test","name":"Test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Initialize([]string{gameDir}); err != nil {
+		t.Fatal(err)
+	}
+	game, err := config.GameDefFromID("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game
+}
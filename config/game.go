@@ -4,10 +4,8 @@ import (
 	"fmt"
 	"fyne.io/fyne/v2"
 	"github.com/kiamev/moogle-mod-manager/util"
-	"golang.org/x/sys/windows/registry"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 )
 
@@ -46,18 +44,20 @@ type (
 		Steam   *SteamVersion `json:"steam,omitempty"`
 	}
 	gameDef struct {
-		ID_                 GameID            `json:"id"`
-		Name_               GameName          `json:"name"`
-		SteamID_            SteamID           `json:"steamID"`
-		Versions_           []Version         `json:"versions"`
-		BaseDir_            BaseDir           `json:"baseDir"`
-		Remote_             Remote            `json:"remote"`
-		AuthorHintDir_      string            `json:"authorHintDir"`
-		DefaultInstallType_ InstallType       `json:"defaultInstallType"`
-		Categories_         []Category        `json:"categories"`
-		LogoPath_           string            `json:"-"`
-		Logo_               fyne.CanvasObject `json:"-"`
-		InstallDir_         string            `json:"-"`
+		ID_                 GameID      `json:"id"`
+		Name_               GameName    `json:"name"`
+		SteamID_            SteamID     `json:"steamID"`
+		Versions_           []Version   `json:"versions"`
+		BaseDir_            BaseDir     `json:"baseDir"`
+		Remote_             Remote      `json:"remote"`
+		AuthorHintDir_      string      `json:"authorHintDir"`
+		DefaultInstallType_ InstallType `json:"defaultInstallType"`
+		Categories_         []Category  `json:"categories"`
+		// LaunchProfiles_ keeps only the profiles a definition may provide, see validateDefined
+		LaunchProfiles_ []*LaunchProfile  `json:"launchProfiles,omitempty"`
+		LogoPath_       string            `json:"-"`
+		Logo_           fyne.CanvasObject `json:"-"`
+		InstallDir_     string            `json:"-"`
 	}
 	GameDef interface {
 		ID() GameID
@@ -69,12 +69,13 @@ type (
 		AuthorHintDir() string
 		DefaultInstallType() InstallType
 		Categories() []Category
+		LaunchProfiles() []*LaunchProfile
 		CategoriesForSelect() []string
 		LogoPath() string
 		SetLogoPath(path string)
 		Logo() fyne.CanvasObject
 		SetLogo(logo fyne.CanvasObject)
-		SteamDirFromRegistry() string
+		SteamDir() string
 	}
 )
 
@@ -114,6 +115,10 @@ func (g *gameDef) Categories() []Category {
 	return g.Categories_
 }
 
+func (g *gameDef) LaunchProfiles() []*LaunchProfile {
+	return g.LaunchProfiles_
+}
+
 func (g *gameDef) CategoriesForSelect() []string {
 	s := make([]string, 0, len(g.Categories_)+1)
 	s = append(s, "")
@@ -253,6 +258,7 @@ func Initialize(dirs []string) (err error) {
 						return err
 					}
 					game.LogoPath_ = logo
+					game.LaunchProfiles_ = definedProfiles(game.LaunchProfiles_)
 					gameDefs = append(gameDefs, &game)
 				}
 			}
@@ -264,18 +270,13 @@ func Initialize(dirs []string) (err error) {
 	return
 }
 
-func (g *gameDef) SteamDirFromRegistry() (dir string) {
-	//only poke into registry for Windows, there's probably a similar method for Mac/Linux
-	if runtime.GOOS == "windows" {
-		key, err := registry.OpenKey(registry.LOCAL_MACHINE, fmt.Sprintf("%s%s", windowsRegLookup, g.SteamID_), registry.QUERY_VALUE)
-		if err != nil {
-			return
-		}
-		if dir, _, err = key.GetStringValue("InstallLocation"); err != nil {
-			dir = ""
-		}
+// SteamDir finds where Steam installed the game, searching every Steam library before falling back to the
+// game's uninstall entry in the Windows registry. It is empty when the game cannot be found.
+func (g *gameDef) SteamDir() string {
+	if dir := FindSteamGameDir(g.SteamID_); dir != "" {
+		return dir
 	}
-	return
+	return registryGameDir(g.SteamID_)
 }
 
 func (g *gameDef) SetLogo(logo fyne.CanvasObject) {
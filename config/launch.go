@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	LaunchKind string
+	// LaunchProfile is one way of starting a game.
+	LaunchProfile struct {
+		Name string     `json:"name"`
+		Kind LaunchKind `json:"kind"`
+		// Path is the executable of an Executable profile, relative to the game's directory unless absolute
+		Path string `json:"path,omitempty"`
+		// Command is run by the system's shell for a Command profile
+		Command string `json:"command,omitempty"`
+		// Args are passed to the executable, or to the game by Steam. $GAME_DIR, $STEAM_ID and environment
+		// variables are expanded.
+		Args       []string          `json:"args,omitempty"`
+		Env        map[string]string `json:"env,omitempty"`
+		WorkingDir string            `json:"workingDir,omitempty"`
+	}
+	// LaunchConfig is how the user launches a game.
+	LaunchConfig struct {
+		Profiles []*LaunchProfile `json:"profiles,omitempty"`
+		// Default is the name of the profile the Launch button uses, Steam when empty
+		Default string `json:"default,omitempty"`
+		// VerifyFirst refuses to launch the game while the installed mods' files have problems
+		VerifyFirst bool `json:"verifyFirst"`
+	}
+)
+
+const (
+	// LaunchSteam asks Steam to run the game
+	LaunchSteam LaunchKind = "Steam"
+	// LaunchExecutable starts the game's executable directly
+	LaunchExecutable LaunchKind = "Executable"
+	// LaunchCommand runs a shell command, such as a Proton or Heroic wrapper
+	LaunchCommand LaunchKind = "Command"
+)
+
+var LaunchKinds = []LaunchKind{LaunchSteam, LaunchExecutable, LaunchCommand}
+
+func (p *LaunchProfile) Validate() error {
+	if p.Name == "" {
+		return errors.New("a launch profile's name is required")
+	}
+	switch p.Kind {
+	case LaunchSteam:
+	case LaunchExecutable:
+		if p.Path == "" {
+			return fmt.Errorf("launch profile [%s] requires the executable's path", p.Name)
+		}
+	case LaunchCommand:
+		if p.Command == "" {
+			return fmt.Errorf("launch profile [%s] requires a command", p.Name)
+		}
+	default:
+		return fmt.Errorf("launch profile [%s] has an unknown kind %s", p.Name, p.Kind)
+	}
+	return nil
+}
+
+// validateDefined checks a profile that comes with a game's definition. Definitions are read from the mod repositories,
+// so their profiles may only have Steam run the game or start an executable inside the game's directory. Running a
+// command, or an executable anywhere else, is left to the profiles the user adds.
+func (p *LaunchProfile) validateDefined() error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	switch p.Kind {
+	case LaunchSteam:
+	case LaunchExecutable:
+		path := filepath.Clean(filepath.FromSlash(p.Path))
+		if filepath.IsAbs(path) || filepath.VolumeName(path) != "" || strings.HasPrefix(path, string(filepath.Separator)) ||
+			path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("launch profile [%s] of a game's definition must start an executable inside the game's directory", p.Name)
+		}
+	default:
+		return fmt.Errorf("launch profile [%s] of a game's definition cannot be a %s profile", p.Name, p.Kind)
+	}
+	if p.WorkingDir != "" {
+		return fmt.Errorf("launch profile [%s] of a game's definition cannot set a working directory", p.Name)
+	}
+	return nil
+}
+
+// definedProfiles returns the profiles of a game's definition that pass validateDefined, leaving out the others.
+func definedProfiles(profiles []*LaunchProfile) (valid []*LaunchProfile) {
+	for _, p := range profiles {
+		if p != nil && p.validateDefined() == nil {
+			valid = append(valid, p)
+		}
+	}
+	return
+}
+
+// LaunchConfig returns how the user launches game, creating it when there is none yet.
+func (c *Configs) LaunchConfig(game GameDef) *LaunchConfig {
+	if c.Launch == nil {
+		c.Launch = make(map[string]*LaunchConfig)
+	}
+	lc, ok := c.Launch[string(game.ID())]
+	if !ok {
+		lc = &LaunchConfig{}
+		c.Launch[string(game.ID())] = lc
+	}
+	return lc
+}
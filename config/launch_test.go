@@ -0,0 +1,34 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateDefined(t *testing.T) {
+	abs, _ := filepath.Abs("game.exe")
+	tests := []struct {
+		name    string
+		p       LaunchProfile
+		wantErr bool
+	}{
+		{"steam", LaunchProfile{Name: "a", Kind: LaunchSteam, Args: []string{"-windowed"}}, false},
+		{"executable", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: "bin/game.exe"}, false},
+		{"inner dots", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: "bin/../game.exe"}, false},
+		{"absolute", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: abs}, true},
+		{"rooted", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: "/usr/bin/sh"}, true},
+		{"outside", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: "../../bin/sh"}, true},
+		{"escapes", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: "bin/../../sh"}, true},
+		{"command", LaunchProfile{Name: "a", Kind: LaunchCommand, Command: "true"}, true},
+		{"working dir", LaunchProfile{Name: "a", Kind: LaunchExecutable, Path: "game.exe", WorkingDir: "/tmp"}, true},
+		{"invalid", LaunchProfile{Name: "a", Kind: LaunchExecutable}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.p.validateDefined(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateDefined() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if got := definedProfiles([]*LaunchProfile{&tests[0].p, nil, &tests[7].p, &tests[1].p}); len(got) != 2 || got[0] != &tests[0].p || got[1] != &tests[1].p {
+		t.Errorf("definedProfiles() = %v", got)
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// vdf is a node of Valve's KeyValues text format used by Steam's .acf and .vdf files. Values are either strings or
+	// nested vdf nodes.
+	vdf map[string]interface{}
+	// SteamManifest is what Steam's appmanifest_<SteamID>.acf records about an installed game.
+	SteamManifest struct {
+		BuildID uint
+		// Depots maps each installed depot to its manifest
+		Depots map[string]uint64
+	}
+)
+
+// SteamLibraries returns the Steam library folders, the directories holding a steamapps directory, of every Steam
+// installation found. Libraries on other drives are read from each installation's libraryfolders.vdf.
+func SteamLibraries() (libraries []string) {
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if dir == "" {
+			return
+		}
+		if d, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = d
+		}
+		if fi, err := os.Stat(filepath.Join(dir, "steamapps")); err != nil || !fi.IsDir() || seen[dir] {
+			return
+		}
+		seen[dir] = true
+		libraries = append(libraries, dir)
+	}
+	for _, root := range steamRoots() {
+		add(root)
+		for _, l := range readLibraryFolders(filepath.Join(root, "steamapps", "libraryfolders.vdf")) {
+			add(l)
+		}
+	}
+	return
+}
+
+// FindSteamGameDir returns the directory the Steam game id is installed in, empty when no library has it.
+func FindSteamGameDir(id SteamID) string {
+	for _, l := range SteamLibraries() {
+		f, err := os.Open(filepath.Join(l, "steamapps", fmt.Sprintf("appmanifest_%s.acf", id)))
+		if err != nil {
+			continue
+		}
+		v, err := parseVDF(f)
+		_ = f.Close()
+		if err != nil {
+			continue
+		}
+		if name := v.node("AppState").str("installdir"); name != "" {
+			dir := filepath.Join(l, "steamapps", "common", name)
+			if _, err = os.Stat(dir); err == nil {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+// readLibraryFolders reads the libraries listed in a libraryfolders.vdf. Newer files hold a block with a "path" for
+// each library, older ones list the paths directly under numbered keys.
+func readLibraryFolders(file string) (libraries []string) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	v, err := parseVDF(f)
+	if err != nil {
+		return
+	}
+	for k, l := range v.node("libraryfolders") {
+		switch t := l.(type) {
+		case vdf:
+			if p := t.str("path"); p != "" {
+				libraries = append(libraries, p)
+			}
+		case string:
+			if _, err = strconv.Atoi(k); err == nil {
+				libraries = append(libraries, t)
+			}
+		}
+	}
+	sort.Strings(libraries)
+	return
+}
+
+// ReadSteamManifest reads the appmanifest of the game installed in gameDir, found in the steamapps directory
+// holding the "common" directory the game is installed in.
+func ReadSteamManifest(gameDir string, id SteamID) (m *SteamManifest, err error) {
+	var (
+		file = filepath.Join(filepath.Dir(filepath.Dir(gameDir)), fmt.Sprintf("appmanifest_%s.acf", id))
+		f    *os.File
+		v    vdf
+	)
+	if f, err = os.Open(file); err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if v, err = parseVDF(f); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", file, err)
+	}
+	app := v.node("AppState")
+	if app == nil {
+		return nil, fmt.Errorf("%s has no AppState", file)
+	}
+	m = &SteamManifest{Depots: make(map[string]uint64)}
+	if b, e := strconv.ParseUint(app.str("buildid"), 10, 64); e == nil {
+		m.BuildID = uint(b)
+	}
+	for depot, d := range app.node("InstalledDepots") {
+		if n, ok := d.(vdf); ok {
+			if mf, e := strconv.ParseUint(n.str("manifest"), 10, 64); e == nil {
+				m.Depots[depot] = mf
+			}
+		}
+	}
+	return
+}
+
+// InstalledVersion finds which of the game's Versions is installed using the game's Steam appmanifest. version is
+// nil when the installed build is none of them, manifest is nil when the game was not installed by Steam.
+func InstalledVersion(game GameDef) (version *Version, manifest *SteamManifest, err error) {
+	var dir string
+	if dir, err = Get().GetDir(game, GameDirKind); err != nil {
+		return
+	}
+	if manifest, err = ReadSteamManifest(dir, game.SteamID()); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	for _, v := range game.Versions() {
+		if v.Steam == nil {
+			continue
+		}
+		if v.Steam.Build != 0 && v.Steam.Build == manifest.BuildID {
+			return &v, manifest, nil
+		}
+		for _, mf := range manifest.Depots {
+			if v.Steam.Manifest != 0 && v.Steam.Manifest == mf {
+				return &v, manifest, nil
+			}
+		}
+	}
+	return
+}
+
+func (v vdf) node(key string) vdf {
+	for k, n := range v {
+		if strings.EqualFold(k, key) {
+			if c, ok := n.(vdf); ok {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func (v vdf) str(key string) string {
+	for k, s := range v {
+		if strings.EqualFold(k, key) {
+			if c, ok := s.(string); ok {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+// parseVDF reads KeyValues text: quoted or bare keys followed by a value or a braced block of more keys.
+func parseVDF(r io.Reader) (vdf, error) {
+	var (
+		br    = bufio.NewReader(r)
+		root  = make(vdf)
+		stack = []vdf{root}
+		key   *string
+	)
+	for {
+		t, quoted, err := vdfToken(br)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		cur := stack[len(stack)-1]
+		switch {
+		case !quoted && t == "{":
+			if key == nil {
+				return nil, errors.New("block without a key")
+			}
+			n := make(vdf)
+			cur[*key] = n
+			stack = append(stack, n)
+			key = nil
+		case !quoted && t == "}":
+			if len(stack) == 1 {
+				return nil, errors.New("unbalanced }")
+			}
+			stack = stack[:len(stack)-1]
+		case key == nil:
+			k := t
+			key = &k
+		default:
+			cur[*key] = t
+			key = nil
+		}
+	}
+	if len(stack) != 1 {
+		return nil, errors.New("unclosed {")
+	}
+	return root, nil
+}
+
+func vdfToken(br *bufio.Reader) (token string, quoted bool, err error) {
+	var (
+		c  rune
+		sb strings.Builder
+	)
+	for {
+		if c, _, err = br.ReadRune(); err != nil {
+			return
+		}
+		if c == '/' {
+			if n, _, e := br.ReadRune(); e == nil && n == '/' {
+				_, err = br.ReadString('\n')
+				if err == io.EOF {
+					err = nil
+				}
+				continue
+			}
+			_ = br.UnreadRune()
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			break
+		}
+	}
+	switch c {
+	case '{', '}':
+		return string(c), false, nil
+	case '"':
+		for {
+			if c, _, err = br.ReadRune(); err != nil {
+				return "", true, errors.New("unterminated string")
+			}
+			if c == '"' {
+				return sb.String(), true, nil
+			}
+			if c == '\\' {
+				if c, _, err = br.ReadRune(); err != nil {
+					return "", true, errors.New("unterminated string")
+				}
+				switch c {
+				case 'n':
+					c = '\n'
+				case 't':
+					c = '\t'
+				}
+			}
+			sb.WriteRune(c)
+		}
+	}
+	sb.WriteRune(c)
+	for {
+		if c, _, err = br.ReadRune(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return sb.String(), false, err
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '{' || c == '}' || c == '"' {
+			_ = br.UnreadRune()
+			return sb.String(), false, nil
+		}
+		sb.WriteRune(c)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    vdf
+		wantErr bool
+	}{
+		{"empty", "", vdf{}, false},
+		{"quoted", `"key" "value"`, vdf{"key": "value"}, false},
+		{"bare", "key value", vdf{"key": "value"}, false},
+		{"nested", "\"a\"\n{\n\t\"b\"\t\t\"1\"\n\t\"c\" { \"d\" \"2\" }\n}", vdf{"a": vdf{"b": "1", "c": vdf{"d": "2"}}}, false},
+		{"comments", "// header\n\"a\" \"1\" // trailing\n\"b\" \"2\"", vdf{"a": "1", "b": "2"}, false},
+		{"escapes", `"path" "C:\\Games\\Steam" "quote" "a\"b"`, vdf{"path": `C:\Games\Steam`, "quote": `a"b`}, false},
+		{"quoted braces", `"a" "{" "b" "}"`, vdf{"a": "{", "b": "}"}, false},
+		{"block without key", `{ "a" "1" }`, nil, true},
+		{"unbalanced", `"a" "1" }`, nil, true},
+		{"unclosed", `"a" { "b" "1"`, nil, true},
+		{"unterminated", `"a" "1`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVDF(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVDF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVDF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLibraryFolders(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"blocks", `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"/home/deck/.local/share/Steam"
+		"apps" { "1173820" "123" }
+	}
+	"1"
+	{
+		"path"		"/run/media/mmcblk0p1"
+	}
+}`, []string{"/home/deck/.local/share/Steam", "/run/media/mmcblk0p1"}},
+		{"numbered paths", `"LibraryFolders"
+{
+	"TimeNextStatsReport"	"1600000000"
+	"ContentStatsID"	"-123"
+	"1"	"D:\\SteamLibrary"
+	"2"	"E:\\Games"
+}`, []string{`D:\SteamLibrary`, `E:\Games`}},
+		{"not vdf", `"libraryfolders" {`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "libraryfolders.vdf")
+			if err := os.WriteFile(f, []byte(tt.in), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := readLibraryFolders(f); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readLibraryFolders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := readLibraryFolders(filepath.Join(t.TempDir(), "missing.vdf")); got != nil {
+		t.Errorf("readLibraryFolders() of a missing file = %q", got)
+	}
+}
+
+func TestReadSteamManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *SteamManifest
+		wantErr bool
+	}{
+		{"installed", `"AppState"
+{
+	"appid"		"1173820"
+	"installdir"		"FINAL FANTASY VI PR"
+	"buildid"		"9876543"
+	"InstalledDepots"
+	{
+		"1173821"
+		{
+			"manifest"		"1234567890123456789"
+			"size"		"123"
+		}
+		"1173822"
+		{
+			"size"		"1"
+		}
+	}
+}`, &SteamManifest{BuildID: 9876543, Depots: map[string]uint64{"1173821": 1234567890123456789}}, false},
+		{"lower case", `"appstate" { "BuildID" "5" }`, &SteamManifest{BuildID: 5, Depots: map[string]uint64{}}, false},
+		{"no build", `"AppState" { "buildid" "" }`, &SteamManifest{Depots: map[string]uint64{}}, false},
+		{"no app state", `"Other" { "buildid" "5" }`, nil, true},
+		{"not vdf", `"AppState" {`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				steamApps = filepath.Join(t.TempDir(), "steamapps")
+				gameDir   = filepath.Join(steamApps, "common", "Game")
+			)
+			if err := os.MkdirAll(gameDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(steamApps, "appmanifest_1173820.acf"), []byte(tt.in), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadSteamManifest(gameDir, "1173820")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadSteamManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadSteamManifest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if _, err := ReadSteamManifest(filepath.Join(t.TempDir(), "common", "Game"), "1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadSteamManifest() of a missing manifest error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// steamRoots are the places Steam may be installed for the user: the native client, the Flatpak and the Snap on
+// Linux and the Steam Deck, and Application Support on macOS.
+func steamRoots() (roots []string) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	if runtime.GOOS == "darwin" {
+		return []string{filepath.Join(home, "Library", "Application Support", "Steam")}
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		roots = append(roots, filepath.Join(xdg, "Steam"))
+	}
+	return append(roots,
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".steam", "root"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", "data", "Steam"),
+		filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam"))
+}
+
+// registryGameDir is only available on Windows.
+func registryGameDir(SteamID) string {
+	return ""
+}
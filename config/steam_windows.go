@@ -0,0 +1,47 @@
+//go:build windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const windowsRegLookup = "Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\Steam App "
+
+// steamRoots are the places Steam may be installed, as recorded by Steam in the registry followed by the default.
+func steamRoots() (roots []string) {
+	for _, k := range []struct {
+		root  registry.Key
+		path  string
+		value string
+	}{
+		{registry.CURRENT_USER, `Software\Valve\Steam`, "SteamPath"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Valve\Steam`, "InstallPath"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Valve\Steam`, "InstallPath"},
+	} {
+		if key, err := registry.OpenKey(k.root, k.path, registry.QUERY_VALUE); err == nil {
+			if dir, _, err := key.GetStringValue(k.value); err == nil && dir != "" {
+				roots = append(roots, filepath.Clean(dir))
+			}
+			_ = key.Close()
+		}
+	}
+	return append(roots, filepath.Join(os.Getenv("ProgramFiles(x86)"), "Steam"))
+}
+
+// registryGameDir is where the game's uninstall entry says Steam installed it.
+func registryGameDir(id SteamID) (dir string) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, fmt.Sprintf("%s%s", windowsRegLookup, id), registry.QUERY_VALUE)
+	if err != nil {
+		return
+	}
+	defer func() { _ = key.Close() }()
+	if dir, _, err = key.GetStringValue("InstallLocation"); err != nil {
+		dir = ""
+	}
+	return
+}
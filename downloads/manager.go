@@ -1,6 +1,8 @@
 package downloads
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/kiamev/moogle-mod-manager/browser"
 	"github.com/kiamev/moogle-mod-manager/config"
@@ -10,15 +12,15 @@ import (
 	"strings"
 )
 
-func Download(game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) (err error) {
+func Download(ctx context.Context, game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) (err error) {
 	k := mod.Kinds()
 	if k.IsHosted() {
-		if err = hosted(game, mod, toInstall); err == nil {
+		if err = hosted(ctx, game, mod, toInstall); err == nil {
 			// Success
 			return
 		}
 	} else if k.Is(mods.CurseForge) {
-		if err = curseForge(game, mod, toInstall); err == nil {
+		if err = curseForge(ctx, game, mod, toInstall); err == nil {
 			// Success
 			return
 		}
@@ -37,36 +39,19 @@ func Download(game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInst
 	return
 }
 
-func hosted(game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) error {
-	var (
-		f   string
-		err error
-	)
+func hosted(ctx context.Context, game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) error {
+	var jobs []*job
 	for _, ti := range toInstall {
 		if len(ti.Download.Hosted.Sources) == 0 {
 			return fmt.Errorf("%s has no download sources", ti.Download.Name)
 		}
-		for _, source := range ti.Download.Hosted.Sources {
-			if f, err = ti.GetDownloadLocation(game, mod); err != nil {
-				return err
-			}
-			if f, err = browser.Download(source, f); err == nil {
-				// success
-				ti.Download.DownloadedArchiveLocation = (*mods.ArchiveLocation)(&f)
-				break
-			}
-		}
-		if ti.Download.DownloadedArchiveLocation == nil || *ti.Download.DownloadedArchiveLocation == "" {
-			return fmt.Errorf("failed to download %s", ti.Download.Hosted.Sources[0])
-		}
-	}
-
-	for _, ti := range toInstall {
-		if ti.Download.DownloadedArchiveLocation == nil || *ti.Download.DownloadedArchiveLocation == "" {
-			return fmt.Errorf("failed to download %s", ti.Download.Hosted.Sources[0])
+		dir, err := ti.GetDownloadLocation(game, mod)
+		if err != nil {
+			return err
 		}
+		jobs = append(jobs, newJob(ti, dir, ti.Download.Hosted.Sources...))
 	}
-	return nil
+	return fetch(ctx, jobs)
 }
 
 func manualDownload(game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) error {
@@ -98,35 +83,50 @@ func manualDownload(game config.GameDef, mod mods.TrackedMod, toInstall []*mods.
 		if ti.Download.DownloadedArchiveLocation == nil || *ti.Download.DownloadedArchiveLocation == "" {
 			return fmt.Errorf("failed to find %s in %s", name, path)
 		}
+		if err = ti.Download.Verify(string(*ti.Download.DownloadedArchiveLocation)); err != nil {
+			if errors.Is(err, mods.ErrIntegrity) {
+				_ = os.Remove(string(*ti.Download.DownloadedArchiveLocation))
+				err = fmt.Errorf("%w, please download %s again", err, name)
+			}
+			ti.Download.DownloadedArchiveLocation = nil
+			return err
+		}
 	}
 	return nil
 }
 
-func curseForge(game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) error {
-	var (
-		f   string
-		err error
-	)
-	for _, ti := range toInstall {
-		for _, i := range toInstall {
-			if f, err = ti.GetDownloadLocation(game, mod); err != nil {
-				return err
-			}
-			if f, err = browser.Download(i.Download.CurseForge.Url, f); err == nil {
-				// success
-				ti.Download.DownloadedArchiveLocation = (*mods.ArchiveLocation)(&f)
-				break
-			}
+// download downloads url into dir and checks it against d's size and SHA-256. A file that does not match,
+// including one left from an earlier download, is deleted and downloaded again once.
+func download(ctx context.Context, d *mods.Download, url, dir string, p browser.Progress) (f string, err error) {
+	if f, err = browser.DownloadWithProgress(ctx, url, dir, p); err != nil {
+		return
+	}
+	if err = d.Verify(f); errors.Is(err, mods.ErrIntegrity) {
+		_ = os.Remove(f)
+		if f, err = browser.DownloadWithProgress(ctx, url, dir, p); err == nil {
+			err = d.Verify(f)
 		}
-		if ti.Download.DownloadedArchiveLocation == nil || *ti.Download.DownloadedArchiveLocation == "" {
-			return fmt.Errorf("failed to download %s", ti.Download.Hosted.Sources[0])
+	}
+	if err != nil {
+		if errors.Is(err, mods.ErrIntegrity) {
+			_ = os.Remove(f)
 		}
+		return "", err
 	}
+	return
+}
 
+func curseForge(ctx context.Context, game config.GameDef, mod mods.TrackedMod, toInstall []*mods.ToInstall) error {
+	var jobs []*job
 	for _, ti := range toInstall {
-		if ti.Download.DownloadedArchiveLocation == nil || *ti.Download.DownloadedArchiveLocation == "" {
-			return fmt.Errorf("failed to download %s", ti.Download.Hosted.Sources[0])
+		if ti.Download.CurseForge == nil || ti.Download.CurseForge.Url == "" {
+			return fmt.Errorf("%s has no download url", ti.Download.Name)
+		}
+		dir, err := ti.GetDownloadLocation(game, mod)
+		if err != nil {
+			return err
 		}
+		jobs = append(jobs, newJob(ti, dir, ti.Download.CurseForge.Url))
 	}
-	return nil
+	return fetch(ctx, jobs)
 }
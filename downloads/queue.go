@@ -0,0 +1,198 @@
+package downloads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kiamev/moogle-mod-manager/browser"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+const (
+	maxConcurrent = 4
+	maxPerHost    = 2
+	// maxRounds is how many times every source of a file is tried before giving up
+	maxRounds = 3
+	backoff   = 2 * time.Second
+)
+
+type (
+	// job is a file to download from the first of its sources that works
+	job struct {
+		ti      *mods.ToInstall
+		sources []string
+		dir     string
+
+		downloaded int64
+		total      int64
+	}
+	queue struct {
+		jobs  []*job
+		hosts map[string]chan struct{}
+		mutex sync.Mutex
+		// failed is set once a job fails so the jobs that have not started are skipped
+		failed bool
+	}
+)
+
+func newJob(ti *mods.ToInstall, dir string, sources ...string) *job {
+	size := ti.Download.Size
+	if size == 0 {
+		size = -1
+	}
+	return &job{
+		ti:      ti,
+		sources: sources,
+		dir:     dir,
+		total:   size,
+	}
+}
+
+// fetch downloads every job, a few at a time, and sets each ToInstall's DownloadedArchiveLocation.
+// Progress is reported for all the jobs together. Cancelling ctx stops the downloads and their retries.
+func fetch(ctx context.Context, jobs []*job) error {
+	var (
+		q = &queue{
+			jobs:  jobs,
+			hosts: make(map[string]chan struct{}),
+		}
+		next   = make(chan *job)
+		errs   = make([]error, len(jobs))
+		wg     sync.WaitGroup
+		worker = func() {
+			defer wg.Done()
+			for j := range next {
+				if err := q.run(ctx, j); err != nil {
+					q.mutex.Lock()
+					q.failed = true
+					q.mutex.Unlock()
+					for i := range jobs {
+						if jobs[i] == j {
+							errs[i] = err
+						}
+					}
+				}
+			}
+		}
+	)
+	for i := 0; i < maxConcurrent && i < len(jobs); i++ {
+		wg.Add(1)
+		go worker()
+	}
+	for _, j := range jobs {
+		next <- j
+	}
+	close(next)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *queue) run(ctx context.Context, j *job) (err error) {
+	var f string
+	for round := 0; round < maxRounds; round++ {
+		if round > 0 {
+			select {
+			case <-time.After(backoff << (round - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		for _, source := range j.sources {
+			q.mutex.Lock()
+			failed := q.failed
+			q.mutex.Unlock()
+			if failed {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			release := q.acquire(ctx, source)
+			if release == nil {
+				return ctx.Err()
+			}
+			f, err = download(ctx, j.ti.Download, source, j.dir, func(_ string, downloaded, total int64) {
+				q.progress(j, downloaded, total)
+			})
+			release()
+			if err == nil {
+				j.ti.Download.DownloadedArchiveLocation = (*mods.ArchiveLocation)(&f)
+				if fi, e := os.Stat(f); e == nil {
+					// Files that were already downloaded report no progress of their own
+					q.progress(j, fi.Size(), fi.Size())
+				}
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Try the next mirror
+		}
+		if !browser.IsRetryable(err) || errors.Is(err, mods.ErrIntegrity) {
+			break
+		}
+	}
+	return fmt.Errorf("failed to download %s: %w", j.sources[0], err)
+}
+
+// acquire waits until fewer than maxPerHost downloads are running from source's host. release is nil when ctx is
+// cancelled first.
+func (q *queue) acquire(ctx context.Context, source string) (release func()) {
+	host := source
+	if u, err := url.Parse(source); err == nil {
+		host = u.Host
+	}
+	q.mutex.Lock()
+	sem, ok := q.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, maxPerHost)
+		q.hosts[host] = sem
+	}
+	q.mutex.Unlock()
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (q *queue) progress(j *job, downloaded, total int64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	j.downloaded = downloaded
+	if total > 0 {
+		j.total = total
+	}
+	var (
+		sumDownloaded int64
+		sumTotal      int64
+		name          = fmt.Sprintf("%d files", len(q.jobs))
+	)
+	if len(q.jobs) == 1 {
+		name = q.jobs[0].ti.Download.Name
+	}
+	for _, qj := range q.jobs {
+		sumDownloaded += qj.downloaded
+		if qj.total < 0 || sumTotal < 0 {
+			sumTotal = -1
+		} else {
+			sumTotal += qj.total
+		}
+	}
+	browser.ReportProgress(name, sumDownloaded, sumTotal)
+}
@@ -0,0 +1,66 @@
+package downloads
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+func TestFetchCancel(t *testing.T) {
+	config.Get().DownloadTimeout = 30
+	config.Get().DownloadStallTimeout = 60
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		part    bool
+	}{
+		{"while downloading", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "1000")
+			_, _ = w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}, true},
+		{"while backing off", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			var (
+				dir         = t.TempDir()
+				ctx, cancel = context.WithCancel(context.Background())
+				j           = &job{
+					ti:      &mods.ToInstall{Download: &mods.Download{Name: "mod"}},
+					sources: []string{srv.URL + "/mod.zip", srv.URL + "/mirror/mod.zip"},
+					dir:     dir,
+					total:   -1,
+				}
+				done = make(chan error)
+			)
+			go func() { done <- fetch(ctx, []*job{j}) }()
+			time.Sleep(200 * time.Millisecond)
+			cancel()
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("fetch() error = %v, want context.Canceled", err)
+				}
+			case <-time.After(backoff):
+				t.Fatal("fetch() kept running after it was cancelled")
+			}
+			if got := util.FileExists(filepath.Join(dir, "mod.zip.part")); got != tt.part {
+				t.Errorf(".part file exists = %v, want %v", got, tt.part)
+			}
+		})
+	}
+}
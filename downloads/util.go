@@ -0,0 +1,33 @@
+package downloads
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/discover/remote/nexus"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+// DownloadLink returns the page a user needs to visit to manually download a Nexus or Google Drive file.
+func DownloadLink(game config.GameDef, d *mods.Download) (string, error) {
+	if d.Nexus != nil {
+		return fmt.Sprintf(nexus.NexusFileDownload, d.Nexus.FileID, game.Remote().Nexus.ID), nil
+	}
+	if d.GoogleDrive != nil {
+		return d.GoogleDrive.Url, nil
+	}
+	return "", errors.New("DownloadLink only works with Nexus and Google Drive")
+}
+
+// IsHostedDownloaded reports if the first source of a hosted download is already in the mod's download directory.
+func IsHostedDownloaded(game config.GameDef, tm mods.TrackedMod, ti *mods.ToInstall) bool {
+	file := strings.Split(ti.Download.Hosted.Sources[0], "/")
+	file = strings.Split(file[len(file)-1], "?")
+	dir, _ := ti.GetDownloadLocation(game, tm)
+	_, err := os.Stat(filepath.Join(dir, file[0]))
+	return err == nil
+}
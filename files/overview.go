@@ -0,0 +1,171 @@
+package files
+
+import (
+	"archive/zip"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+// Contested is a path, or an entry of Archive, that more than one mod installed or wanted.
+type Contested struct {
+	Path    string
+	Archive string
+	// Winner is the mod whose file is there now, empty when none of the mods' files is
+	Winner mods.ModID
+	// Losers are the mods that did not get their file
+	Losers []mods.ModID
+}
+
+// Overview lists every contested path of the game, loose files first then archive entries, each sorted by path.
+// Besides the files the mods lost when installed, what each mod recorded it wanted is counted, so a file a mod won and
+// a later mod took is listed too.
+func Overview(game config.GameDef) (contested []*Contested, err error) {
+	var (
+		mt       = ModTracker(game)
+		loose    = make(map[string]*Contested)
+		entries  = make(map[string]map[string][]mods.ModID)
+		gameDir  string
+		archives []*Contested
+	)
+	for id, ft := range mt.Mods {
+		for _, f := range ft.Files.Keys() {
+			overviewFile(loose, f).Winner = id
+		}
+		for _, f := range ft.Lost.Keys() {
+			overviewFile(loose, f).lost(id)
+		}
+		for _, f := range ft.Wanted.Keys() {
+			if !ft.Files.Contains(f) {
+				overviewFile(loose, f).lost(id)
+			}
+		}
+		for a, s := range ft.ArchiveFiles {
+			for _, f := range s.Keys() {
+				overviewEntry(entries, a, f, id)
+			}
+		}
+		for a, s := range ft.WantedArchiveFiles {
+			for _, f := range s.Keys() {
+				overviewEntry(entries, a, f, id)
+			}
+		}
+	}
+	for _, c := range loose {
+		if len(c.Losers) > 0 && (c.Winner != "" || len(c.Losers) > 1) {
+			sortIDs(c.Losers)
+			contested = append(contested, c)
+		}
+	}
+	sort.Slice(contested, func(i, j int) bool { return contested[i].Path < contested[j].Path })
+
+	if len(entries) > 0 {
+		if gameDir, err = config.Get().GetDir(game, config.GameDirKind); err != nil {
+			return
+		}
+	}
+	for a, m := range entries {
+		for f, ids := range m {
+			if len(ids) < 2 {
+				continue
+			}
+			sortIDs(ids)
+			archives = append(archives, archiveWinner(game, filepath.Join(gameDir, a), a, f, ids))
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		if archives[i].Archive != archives[j].Archive {
+			return archives[i].Archive < archives[j].Archive
+		}
+		return archives[i].Path < archives[j].Path
+	})
+	contested = append(contested, archives...)
+	return
+}
+
+func (c *Contested) String() string {
+	var (
+		sb     strings.Builder
+		winner = string(c.Winner)
+		losers = make([]string, len(c.Losers))
+	)
+	if winner == "" {
+		winner = "unknown"
+	}
+	for i, id := range c.Losers {
+		losers[i] = string(id)
+	}
+	sb.WriteString(c.Path)
+	if c.Archive != "" {
+		sb.WriteString(" in " + c.Archive)
+	}
+	sb.WriteString(fmt.Sprintf(": %s wins, %s lost", winner, strings.Join(losers, ", ")))
+	return sb.String()
+}
+
+func overviewFile(m map[string]*Contested, f string) *Contested {
+	c, ok := m[f]
+	if !ok {
+		c = &Contested{Path: f}
+		m[f] = c
+	}
+	return c
+}
+
+func (c *Contested) lost(id mods.ModID) {
+	for _, l := range c.Losers {
+		if l == id {
+			return
+		}
+	}
+	c.Losers = append(c.Losers, id)
+}
+
+func overviewEntry(entries map[string]map[string][]mods.ModID, archive string, f string, id mods.ModID) {
+	if entries[archive] == nil {
+		entries[archive] = make(map[string][]mods.ModID)
+	}
+	for _, i := range entries[archive][f] {
+		if i == id {
+			return
+		}
+	}
+	entries[archive][f] = append(entries[archive][f], id)
+}
+
+// archiveWinner finds which of ids injected the entry f that is in the archive now by comparing its hash with the
+// ones recorded. Only zip archives can be read, in other archives the winner is unknown.
+func archiveWinner(game config.GameDef, absArchive string, archive string, f string, ids []mods.ModID) *Contested {
+	c := &Contested{Path: f, Archive: archive}
+	if r, err := zip.OpenReader(absArchive); err == nil {
+		defer func() { _ = r.Close() }()
+		for _, e := range r.File {
+			if e.Name != filepath.ToSlash(f) {
+				continue
+			}
+			if h, err := hashZipFile(e); err == nil {
+				for _, id := range ids {
+					if hash, found := ArchiveHash(game, id, archive, f); found && hash == h {
+						c.Winner = id
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+	for _, id := range ids {
+		if id != c.Winner {
+			c.Losers = append(c.Losers, id)
+		}
+	}
+	return c
+}
+
+func sortIDs(ids []mods.ModID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
@@ -0,0 +1,51 @@
+package files
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+func TestOverview(t *testing.T) {
+	game := configtest.Game(t)
+	config.Get().GameDirs[string(game.ID())] = &config.GameDir{Dir: filepath.Join(config.PWD, "game")}
+
+	// a installed x and y, b then took x and lost z to a, c only wanted an entry a also injected
+	SetWanted(game, "a", []string{"x", "y", "z"}, map[string][]string{"data.zip": {"e"}})
+	SetFiles(game, "a", "x", "y", "z")
+	AppendArchiveFiles(game, "a", "data.zip", "e")
+	SetWanted(game, "b", []string{"x", "z"}, nil)
+	RemoveFiles(game, "a", "x")
+	SetFiles(game, "b", "x")
+	SetWanted(game, "c", nil, map[string][]string{"data.zip": {"e", "f"}})
+
+	contested, err := Overview(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Contested{
+		{Path: "x", Winner: "b", Losers: []mods.ModID{"a"}},
+		{Path: "z", Winner: "a", Losers: []mods.ModID{"b"}},
+		{Path: "e", Archive: "data.zip", Losers: []mods.ModID{"a", "c"}},
+	}
+	var got []Contested
+	for _, c := range contested {
+		got = append(got, *c)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Overview() = %v, want %v", got, want)
+	}
+
+	RemoveFiles(game, "b", "x")
+	ClearWanted(game, "b")
+	if contested, err = Overview(game); err != nil {
+		t.Fatal(err)
+	}
+	if len(contested) != 1 || contested[0].Archive == "" {
+		t.Errorf("Overview() after b is uninstalled = %v", contested)
+	}
+}
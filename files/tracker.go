@@ -1,6 +1,7 @@
 package files
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/kiamev/moogle-mod-manager/collections"
@@ -8,8 +9,8 @@ import (
 	"github.com/kiamev/moogle-mod-manager/mods"
 	uu "github.com/kiamev/moogle-mod-manager/ui/util"
 	"github.com/kiamev/moogle-mod-manager/util"
+	"os"
 	"path/filepath"
-	"syscall"
 )
 
 const file = "filetracker.json"
@@ -20,19 +21,41 @@ type (
 	}
 	modTracker struct {
 		Mods map[mods.ModID]*fileTracker `json:"mods"`
-		//Backups collections.Set[string]     `json:"backup"`
+		// Backups maps the absolute path of every backup the manager created to what it is a backup of
+		Backups map[string]*Backup `json:"backups,omitempty"`
 	}
 	fileTracker struct {
 		Files        collections.Set[string]            `json:"files,omitempty"`
 		ArchiveFiles map[string]collections.Set[string] `json:"archive_files,omitempty"`
+		// Hashes holds the SHA-256 of each of Files when it was installed
+		Hashes map[string]string `json:"hashes,omitempty"`
+		// ArchiveHashes holds the SHA-256 of each of ArchiveFiles when it was injected
+		ArchiveHashes map[string]map[string]string `json:"archive_hashes,omitempty"`
+		// Lost holds the files the mod would have installed if another mod had not won them
+		Lost collections.Set[string] `json:"lost,omitempty"`
+		// Wanted holds every file the mod's install places, won or lost, and WantedArchiveFiles every entry it
+		// injects into each archive, recorded when it is installed for the conflict overview
+		Wanted             collections.Set[string]            `json:"wanted,omitempty"`
+		WantedArchiveFiles map[string]collections.Set[string] `json:"wanted_archive_files,omitempty"`
+	}
+	Backup struct {
+		// File is the game file, or the file within Archive, that was backed up
+		File    string `json:"file"`
+		Archive string `json:"archive,omitempty"`
+		Hash    string `json:"hash"`
 	}
 )
 
-var tracker = &gameTracker{Games: make(map[config.GameID]*modTracker)}
+var (
+	tracker = &gameTracker{Games: make(map[config.GameID]*modTracker)}
+	// batching is above zero while Batch holds back saving the tracker, unsaved is set when a save was held back
+	batching int
+	unsaved  bool
+)
 
 func Initialize() error {
 	if err := util.LoadFromFile(filepath.Join(config.PWD, file), tracker); err != nil {
-		if !errors.Is(err, syscall.ERROR_FILE_NOT_FOUND) {
+		if !errors.Is(err, os.ErrNotExist) {
 			return fmt.Errorf("failed to load file tracker: %v", err)
 		}
 	}
@@ -43,11 +66,14 @@ func ModTracker(game config.GameDef) *modTracker {
 	mt, ok := tracker.Games[game.ID()]
 	if !ok {
 		mt = &modTracker{
-			Mods: make(map[mods.ModID]*fileTracker),
-			//Backups: collections.NewSet[string](),
+			Mods:    make(map[mods.ModID]*fileTracker),
+			Backups: make(map[string]*Backup),
 		}
 		tracker.Games[game.ID()] = mt
 	}
+	if mt.Backups == nil {
+		mt.Backups = make(map[string]*Backup)
+	}
 	return mt
 }
 
@@ -62,6 +88,18 @@ func modFiles(game config.GameDef, modID mods.ModID) *fileTracker {
 		}
 		mt.Mods[modID] = ft
 	}
+	if ft.Hashes == nil {
+		ft.Hashes = make(map[string]string)
+	}
+	if ft.ArchiveHashes == nil {
+		ft.ArchiveHashes = make(map[string]map[string]string)
+	}
+	if ft.Lost.Map == nil {
+		ft.Lost = collections.NewSet[string]()
+	}
+	if ft.Wanted.Map == nil {
+		ft.Wanted = collections.NewSet[string]()
+	}
 	return ft
 }
 
@@ -82,9 +120,23 @@ func EmptyMods(game config.GameDef) (result []mods.ModID) {
 	return
 }
 
-//func Backups(game config.GameDef) collections.Set[string] {
-//	return ModTracker(game).Backups
-//}
+// Hash returns the SHA-256 recorded when file was installed by modID.
+func Hash(game config.GameDef, modID mods.ModID, file string) (hash string, found bool) {
+	hash, found = modFiles(game, modID).Hashes[file]
+	return
+}
+
+// ArchiveHash returns the SHA-256 recorded when file was injected into archive by modID.
+func ArchiveHash(game config.GameDef, modID mods.ModID, archive string, file string) (hash string, found bool) {
+	if m, ok := modFiles(game, modID).ArchiveHashes[archive]; ok {
+		hash, found = m[file]
+	}
+	return
+}
+
+func Backups(game config.GameDef) map[string]*Backup {
+	return ModTracker(game).Backups
+}
 
 func HasFile(game config.GameDef, file string) (modID mods.ModID, found bool) {
 	var ft *fileTracker
@@ -108,9 +160,10 @@ func HasArchiveFile(game config.GameDef, archive string, file string) (modID mod
 	return
 }
 
-//func HasBackup(game config.GameDef, file string) bool {
-//	return ModTracker(game).Backups.Contains(file)
-//}
+func HasBackup(game config.GameDef, backup string) bool {
+	_, found := ModTracker(game).Backups[backup]
+	return found
+}
 
 func SetFiles(game config.GameDef, modID mods.ModID, files ...string) {
 	var (
@@ -118,10 +171,61 @@ func SetFiles(game config.GameDef, modID mods.ModID, files ...string) {
 	)
 	for _, f := range files {
 		ft.Files.Set(f)
+		ft.Lost.Remove(f)
 	}
 	tracker.save()
 }
 
+// SetLost records the files modID did not install because another mod won them.
+func SetLost(game config.GameDef, modID mods.ModID, files ...string) {
+	ft := modFiles(game, modID)
+	for _, f := range files {
+		ft.Lost.Set(f)
+	}
+	tracker.save()
+}
+
+// Lost returns the files modID did not install because another mod won them.
+func Lost(game config.GameDef, modID mods.ModID) collections.Set[string] {
+	return modFiles(game, modID).Lost
+}
+
+// ClearLost forgets the files modID lost, for when it is uninstalled.
+func ClearLost(game config.GameDef, modID mods.ModID) {
+	if ft, ok := ModTracker(game).Mods[modID]; ok && ft.Lost.Len() > 0 {
+		ft.Lost = collections.NewSet[string]()
+		tracker.save()
+	}
+}
+
+// SetWanted records the files and the entries of each archive modID's install places, won or lost, replacing the ones
+// recorded when it was installed before.
+func SetWanted(game config.GameDef, modID mods.ModID, files []string, archiveFiles map[string][]string) {
+	ft := modFiles(game, modID)
+	ft.Wanted = collections.NewSet[string]()
+	for _, f := range files {
+		ft.Wanted.Set(f)
+	}
+	ft.WantedArchiveFiles = make(map[string]collections.Set[string])
+	for a, fs := range archiveFiles {
+		s := collections.NewSet[string]()
+		for _, f := range fs {
+			s.Set(f)
+		}
+		ft.WantedArchiveFiles[a] = s
+	}
+	tracker.save()
+}
+
+// ClearWanted forgets what modID's install places, for when it is uninstalled.
+func ClearWanted(game config.GameDef, modID mods.ModID) {
+	if ft, ok := ModTracker(game).Mods[modID]; ok && (ft.Wanted.Len() > 0 || len(ft.WantedArchiveFiles) > 0) {
+		ft.Wanted = collections.NewSet[string]()
+		ft.WantedArchiveFiles = nil
+		tracker.save()
+	}
+}
+
 func AppendArchiveFiles(game config.GameDef, modID mods.ModID, archive string, files ...string) {
 	var (
 		ft = modFiles(game, modID)
@@ -142,25 +246,49 @@ func AppendArchiveFiles(game config.GameDef, modID mods.ModID, archive string, f
 	tracker.save()
 }
 
-/*func SetBackups(game config.GameDef, backups ...string) {
-	mt := ModTracker(game)
-	for _, f := range backups {
-		mt.Backups.Set(f)
+// SetHash records the SHA-256 of the installed file.
+func SetHash(game config.GameDef, modID mods.ModID, file string, hash string) {
+	modFiles(game, modID).Hashes[file] = hash
+	tracker.save()
+}
+
+// SetArchiveHashes records the SHA-256 of files, keyed by their path within archive.
+func SetArchiveHashes(game config.GameDef, modID mods.ModID, archive string, hashes map[string]string) {
+	ft := modFiles(game, modID)
+	m, found := ft.ArchiveHashes[archive]
+	if !found {
+		m = make(map[string]string)
+		ft.ArchiveHashes[archive] = m
+	}
+	for f, h := range hashes {
+		m[f] = h
 	}
-	save()
-}*/
+	tracker.save()
+}
+
+// SetBackup records that backup holds the original of file, or of file within archive when archive is not empty.
+func SetBackup(game config.GameDef, backup string, file string, archive string, hash string) {
+	ModTracker(game).Backups[backup] = &Backup{
+		File:    file,
+		Archive: archive,
+		Hash:    hash,
+	}
+	tracker.save()
+}
 
-/*func RemoveBackups(game config.GameDef, backups ...string) {
+func RemoveBackups(game config.GameDef, backups ...string) {
 	mt := ModTracker(game)
 	for _, bu := range backups {
-		mt.Backups.Remove(bu)
+		delete(mt.Backups, bu)
 	}
-	save()
-}*/
+	tracker.save()
+}
 
 func RemoveFiles(game config.GameDef, modID mods.ModID, files ...string) {
+	ft := modFiles(game, modID)
 	for _, f := range files {
-		modFiles(game, modID).Files.Remove(f)
+		ft.Files.Remove(f)
+		delete(ft.Hashes, f)
 	}
 	tracker.save()
 }
@@ -188,12 +316,49 @@ func RemoveArchiveFiles(game config.GameDef, modID mods.ModID, archive string, f
 			s.Remove(f)
 			m[archive] = s
 		}
+		delete(ft.ArchiveHashes[archive], f)
 	}
 	ft.ArchiveFiles = m
 	tracker.save()
 }
 
+// TrackerState returns the game's part of the file tracker so it can be put back with SetTrackerState.
+func TrackerState(game config.GameDef) ([]byte, error) {
+	return json.Marshal(ModTracker(game))
+}
+
+// SetTrackerState replaces the game's part of the file tracker with one returned by TrackerState.
+func SetTrackerState(game config.GameDef, state []byte) error {
+	mt := &modTracker{}
+	if err := json.Unmarshal(state, mt); err != nil {
+		return fmt.Errorf("failed to read file tracker state: %v", err)
+	}
+	if mt.Mods == nil {
+		mt.Mods = make(map[mods.ModID]*fileTracker)
+	}
+	tracker.Games[game.ID()] = mt
+	tracker.save()
+	return nil
+}
+
+// Batch runs f with saving the file tracker held back until it returns, then saves it once. A step that tracks many
+// files runs in a batch so filetracker.json is not rewritten for every file.
+func Batch(f func()) {
+	batching++
+	defer func() {
+		if batching--; batching == 0 && unsaved {
+			unsaved = false
+			tracker.save()
+		}
+	}()
+	f()
+}
+
 func (t *gameTracker) save() {
+	if batching > 0 {
+		unsaved = true
+		return
+	}
 	if err := util.SaveToFile(filepath.Join(config.PWD, file), t); err != nil {
 		uu.ShowErrorLong(fmt.Errorf("failed to save file tracker: %v", err))
 	}
@@ -0,0 +1,39 @@
+package files
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+func TestBatch(t *testing.T) {
+	game := configtest.Game(t)
+	saved := filepath.Join(config.PWD, file)
+	Batch(func() {
+		Batch(func() {
+			SetFiles(game, "mod", "a")
+			SetHash(game, "mod", "a", "hash")
+		})
+		if util.FileExists(saved) {
+			t.Error("tracker saved before the outer batch returned")
+		}
+		SetBackup(game, "backup", "a", "", "hash")
+	})
+	if !util.FileExists(saved) {
+		t.Fatal("tracker not saved once the batch returned")
+	}
+
+	tracker = &gameTracker{}
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := Hash(game, "mod", "a"); h != "hash" {
+		t.Errorf("hash = %q, want %q", h, "hash")
+	}
+	if !HasBackup(game, "backup") {
+		t.Error("backup not saved")
+	}
+}
@@ -0,0 +1,91 @@
+package files
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+)
+
+// Leftovers is what keeps the game from being vanilla after every mod was uninstalled and the backups restored.
+type Leftovers struct {
+	// Tracked are the files, and archive entries, the file tracker still holds for a mod
+	Tracked []string
+	// Backups are the tracked backups that were not restored
+	Backups []string
+	// BackupFiles are the files still in the game's backup directory
+	BackupFiles []string
+}
+
+// CheckVanilla finds the Leftovers of the game.
+func CheckVanilla(game config.GameDef) (l *Leftovers, err error) {
+	l = &Leftovers{}
+	for id, ft := range ModTracker(game).Mods {
+		for _, f := range ft.Files.Keys() {
+			l.Tracked = append(l.Tracked, fmt.Sprintf("%s: %s", id, f))
+		}
+		for a, s := range ft.ArchiveFiles {
+			for _, f := range s.Keys() {
+				l.Tracked = append(l.Tracked, fmt.Sprintf("%s: %s in %s", id, f, a))
+			}
+		}
+	}
+	for bu := range Backups(game) {
+		l.Backups = append(l.Backups, bu)
+	}
+	var backupDir string
+	if backupDir, err = config.Get().GetDir(game, config.BackupDirKind); err != nil {
+		return
+	}
+	if err = filepath.WalkDir(backupDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == backupDir {
+				// No backup directory is as empty as it gets
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			l.BackupFiles = append(l.BackupFiles, path)
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+	sort.Strings(l.Tracked)
+	sort.Strings(l.Backups)
+	sort.Strings(l.BackupFiles)
+	return
+}
+
+// Clean reports whether nothing is left over.
+func (l *Leftovers) Clean() bool {
+	return len(l.Tracked) == 0 && len(l.Backups) == 0 && len(l.BackupFiles) == 0
+}
+
+func (l *Leftovers) String() string {
+	if l.Clean() {
+		return "No tracked files or backups remain, the game is vanilla."
+	}
+	var sb strings.Builder
+	for _, s := range []struct {
+		title string
+		items []string
+	}{
+		{"Files still tracked as installed by a mod", l.Tracked},
+		{"Backups that were not restored", l.Backups},
+		{"Files left in the backup directory", l.BackupFiles},
+	} {
+		if len(s.items) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d):\n", s.title, len(s.items)))
+		for _, i := range s.items {
+			sb.WriteString("  " + i + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+package files
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+type IssueKind byte
+
+const (
+	// Missing files were installed but are no longer there
+	Missing IssueKind = iota
+	// Modified files no longer match the hash recorded when they were installed
+	Modified
+	// BackupMissing means the original of an installed file can no longer be restored
+	BackupMissing
+	// BackupModified backups no longer match the hash recorded when they were created
+	BackupModified
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case Missing:
+		return "missing"
+	case Modified:
+		return "modified"
+	case BackupMissing:
+		return "backup missing"
+	case BackupModified:
+		return "backup modified"
+	}
+	return "unknown"
+}
+
+type Issue struct {
+	Kind IssueKind
+	Mod  mods.ModID
+	// File is the installed game file, or the file within Archive
+	File    string
+	Archive string
+	Backup  string
+}
+
+// CanRepair reports if the issue can be fixed by installing the file from the mod's download again.
+func (i *Issue) CanRepair() bool {
+	return i.Kind == Missing || i.Kind == Modified
+}
+
+func (i *Issue) String() string {
+	f := i.File
+	if i.Archive != "" {
+		f = fmt.Sprintf("%s in %s", i.File, i.Archive)
+	}
+	if i.Kind == BackupMissing || i.Kind == BackupModified {
+		return fmt.Sprintf("%s: %s (%s)", i.Kind, i.Backup, f)
+	}
+	return fmt.Sprintf("%s: %s", i.Kind, f)
+}
+
+// VerifyAll checks the files of every mod with tracked files.
+func VerifyAll(game config.GameDef) (issues []*Issue, err error) {
+	var ids []mods.ModID
+	for id := range ModTracker(game).Mods {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		var i []*Issue
+		if i, err = Verify(game, id); err != nil {
+			return
+		}
+		issues = append(issues, i...)
+	}
+	return
+}
+
+// Verify checks that every file modID installed, and the backups of the files it replaced, still match what was
+// recorded when the mod was installed. Files installed before hashes were recorded are only checked for existence.
+func Verify(game config.GameDef, modID mods.ModID) (issues []*Issue, err error) {
+	var (
+		ft      = modFiles(game, modID)
+		gameDir string
+	)
+	for _, f := range ft.Files.Keys() {
+		if k, bad := check(f, ft.Hashes[f]); bad {
+			issues = append(issues, &Issue{Kind: k, Mod: modID, File: f})
+		}
+	}
+
+	if len(ft.ArchiveFiles) > 0 {
+		if gameDir, err = config.Get().GetDir(game, config.GameDirKind); err != nil {
+			return
+		}
+	}
+	for a, s := range ft.ArchiveFiles {
+		issues = append(issues, verifyArchive(filepath.Join(gameDir, a), a, s.Keys(), ft.ArchiveHashes[a], modID)...)
+	}
+
+	for bu, b := range ModTracker(game).Backups {
+		if b.Archive == "" && !ft.Files.Contains(b.File) {
+			continue
+		}
+		if s, found := ft.ArchiveFiles[b.Archive]; b.Archive != "" && (!found || !s.Contains(b.File)) {
+			continue
+		}
+		if k, bad := check(bu, b.Hash); bad {
+			if k == Missing {
+				k = BackupMissing
+			} else {
+				k = BackupModified
+			}
+			issues = append(issues, &Issue{Kind: k, Mod: modID, File: b.File, Archive: b.Archive, Backup: bu})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Archive != issues[j].Archive {
+			return issues[i].Archive < issues[j].Archive
+		}
+		return issues[i].File < issues[j].File
+	})
+	return
+}
+
+func check(file string, hash string) (IssueKind, bool) {
+	if !util.FileExists(file) {
+		return Missing, true
+	}
+	if hash != "" {
+		if h, err := util.HashFile(file); err != nil || h != hash {
+			return Modified, true
+		}
+	}
+	return 0, false
+}
+
+// verifyArchive checks the injected files against hashes. Only zip archives can be read, for other archives
+// just the archive's existence is checked.
+func verifyArchive(absArchive string, archive string, injected []string, hashes map[string]string, modID mods.ModID) (issues []*Issue) {
+	if _, err := os.Stat(absArchive); err != nil {
+		for _, f := range injected {
+			issues = append(issues, &Issue{Kind: Missing, Mod: modID, File: f, Archive: archive})
+		}
+		return
+	}
+	r, err := zip.OpenReader(absArchive)
+	if err != nil {
+		return
+	}
+	defer func() { _ = r.Close() }()
+
+	entries := make(map[string]*zip.File)
+	for _, f := range r.File {
+		entries[f.Name] = f
+	}
+	for _, f := range injected {
+		e, found := entries[filepath.ToSlash(f)]
+		if !found {
+			issues = append(issues, &Issue{Kind: Missing, Mod: modID, File: f, Archive: archive})
+			continue
+		}
+		if hash, ok := hashes[f]; ok && hash != "" {
+			if h, err := hashZipFile(e); err != nil || h != hash {
+				issues = append(issues, &Issue{Kind: Modified, Mod: modID, File: f, Archive: archive})
+			}
+		}
+	}
+	return
+}
+
+func hashZipFile(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rc.Close() }()
+	return util.Hash(rc)
+}
@@ -0,0 +1,359 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// The journal is written ahead of every change an install makes to the game's files so an install that
+// was interrupted by a crash or power loss can be undone, or finished, the next time the manager starts.
+
+const (
+	dirName  = "journal"
+	fileName = "journal.jsonl"
+)
+
+const (
+	opBegin op = "begin"
+	// opBackup: the game file From is moved to the backup To
+	opBackup op = "backup"
+	// opReplace: the game file From, whose original is already backed up, is moved into the journal at To
+	opReplace op = "replace"
+	// opUntrack: the game file From is no longer tracked as installed by the mod Mod, whose file had the hash Hash
+	opUntrack op = "untrack"
+	// opExtract: the backup To is extracted from the archive From
+	opExtract op = "extract"
+	// opInstall: the mod's file From is moved to the game file To
+	opInstall op = "install"
+	// opArchive: Files are injected into the game archive From, the entries among them it already had, Replaced, are
+	// saved under To first
+	opArchive op = "archive"
+	// opInstalled: every file is in place and tracked
+	opInstalled op = "installed"
+)
+
+type (
+	op    string
+	entry struct {
+		Op      op            `json:"op"`
+		Game    config.GameID `json:"game,omitempty"`
+		Mod     mods.ModID    `json:"mod,omitempty"`
+		From    string        `json:"from,omitempty"`
+		To      string        `json:"to,omitempty"`
+		Archive string        `json:"archive,omitempty"`
+		Files   []string      `json:"files,omitempty"`
+		// Replaced are the entries of Files the archive had before they were injected
+		Replaced []string `json:"replaced,omitempty"`
+		Hash     string   `json:"hash,omitempty"`
+	}
+	Journal struct {
+		f     *os.File
+		game  config.GameDef
+		mod   mods.ModID
+		saved int
+	}
+)
+
+func dir() string {
+	return filepath.Join(config.PWD, dirName)
+}
+
+func file() string {
+	return filepath.Join(dir(), fileName)
+}
+
+// Begin starts the journal for installing mod. Only one journal can be open at a time.
+func Begin(game config.GameDef, mod mods.ModID) (*Journal, error) {
+	if _, err := os.Stat(file()); err == nil {
+		return nil, errors.New("an unfinished install journal exists, restart the mod manager to recover it")
+	}
+	if err := os.MkdirAll(dir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the install journal: %v", err)
+	}
+	f, err := os.OpenFile(file(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the install journal: %v", err)
+	}
+	j := &Journal{f: f, game: game, mod: mod}
+	if err = j.write(entry{Op: opBegin, Game: game.ID(), Mod: mod}); err != nil {
+		j.close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Backup must be called before the game file is moved to backup.
+func (j *Journal) Backup(file, backup string) error {
+	return j.write(entry{Op: opBackup, From: file, To: backup})
+}
+
+// Replace moves the game file, whose original is already backed up, into the journal so a rollback can put it back.
+// It is usually another mod's file.
+func (j *Journal) Replace(file string) error {
+	to := j.savePath(file)
+	if err := j.write(entry{Op: opReplace, From: file, To: to}); err != nil {
+		return err
+	}
+	if err := util.MoveFile(file, to); err != nil {
+		return fmt.Errorf("failed to move %s to the install journal: %v", file, err)
+	}
+	return nil
+}
+
+// Untrack must be called before file is removed from the files tracked for owner because the mod being installed
+// replaces it.
+func (j *Journal) Untrack(owner mods.ModID, file string) error {
+	h, _ := files.Hash(j.game, owner, file)
+	return j.write(entry{Op: opUntrack, Mod: owner, From: file, Hash: h})
+}
+
+// Extract must be called before a file that has no backup yet is extracted from archive to backup.
+func (j *Journal) Extract(archive, backup string) error {
+	return j.write(entry{Op: opExtract, From: archive, To: backup})
+}
+
+// Install must be called before the mod's file from is moved to the game file to.
+func (j *Journal) Install(from, to string) error {
+	return j.write(entry{Op: opInstall, From: from, To: to})
+}
+
+// Archive saves the entries of the game archive absArchive that injected replaces into the journal before the files
+// are injected, name being the archive relative to the game directory. Only those entries are kept: a rollback puts
+// them back and deletes the entries the install added.
+func (j *Journal) Archive(absArchive string, name string, injected []string) error {
+	var (
+		to       = j.savePath(absArchive)
+		replaced []string
+	)
+	for _, f := range injected {
+		found, err := archive.Contains(absArchive, f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", absArchive, err)
+		}
+		if !found {
+			continue
+		}
+		if err = archive.ExtractEntry(absArchive, f, filepath.Join(to, f)); err != nil {
+			return fmt.Errorf("failed to copy %s from %s to the install journal: %v", f, absArchive, err)
+		}
+		replaced = append(replaced, f)
+	}
+	return j.write(entry{Op: opArchive, From: absArchive, To: to, Archive: name, Files: injected, Replaced: replaced})
+}
+
+// Installed marks every file as moved and tracked. From here on recovery finishes the install instead of undoing it.
+func (j *Journal) Installed() error {
+	return j.write(entry{Op: opInstalled})
+}
+
+// Commit ends the journal once the mod is enabled.
+func (j *Journal) Commit() error {
+	j.close()
+	return os.RemoveAll(dir())
+}
+
+// Rollback undoes everything written to the journal and ends it.
+func (j *Journal) Rollback() error {
+	j.close()
+	return Recover()
+}
+
+// savePath returns where in the journal a copy of file is kept.
+func (j *Journal) savePath(file string) string {
+	j.saved++
+	return filepath.Join(dir(), strconv.Itoa(j.saved)+"_"+filepath.Base(file))
+}
+
+func (j *Journal) write(e entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err = j.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write the install journal: %v", err)
+	}
+	if err = j.f.Sync(); err != nil {
+		return fmt.Errorf("failed to write the install journal: %v", err)
+	}
+	return nil
+}
+
+func (j *Journal) close() {
+	if j.f != nil {
+		_ = j.f.Close()
+		j.f = nil
+	}
+}
+
+// Recover finishes or undoes an install that did not complete. It must run after the file and mod trackers are
+// initialized and before any other action.
+func Recover() error {
+	entries, err := read()
+	if err != nil || len(entries) == 0 {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		if err == nil {
+			_ = os.RemoveAll(dir())
+		}
+		return err
+	}
+
+	var (
+		begin     = entries[0]
+		installed bool
+		game      config.GameDef
+	)
+	if begin.Op != opBegin {
+		return errors.New("install journal is missing its header")
+	}
+	if game, err = config.GameDefFromID(begin.Game); err != nil {
+		return fmt.Errorf("failed to recover the install journal: %v", err)
+	}
+	for _, e := range entries {
+		if e.Op == opInstalled {
+			installed = true
+		}
+	}
+	files.Batch(func() {
+		if installed {
+			err = rollForward(game, begin.Mod, entries[1:])
+		} else {
+			err = rollBack(game, begin.Mod, entries[1:])
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to recover the install of %s: %v", begin.Mod, err)
+	}
+	return os.RemoveAll(dir())
+}
+
+func read() (entries []entry, err error) {
+	var f *os.File
+	if f, err = os.Open(file()); err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for s.Scan() {
+		var e entry
+		if json.Unmarshal(s.Bytes(), &e) != nil {
+			// A write cut short by the crash, nothing after it was done
+			break
+		}
+		entries = append(entries, e)
+	}
+	return entries, s.Err()
+}
+
+func rollForward(game config.GameDef, modID mods.ModID, entries []entry) error {
+	for _, e := range entries {
+		switch e.Op {
+		case opInstall:
+			if util.FileExists(e.To) {
+				files.SetFiles(game, modID, e.To)
+				if h, err := util.HashFile(e.To); err == nil {
+					files.SetHash(game, modID, e.To, h)
+				}
+			}
+		case opUntrack:
+			files.RemoveFiles(game, e.Mod, e.From)
+		case opArchive:
+			files.AppendArchiveFiles(game, modID, e.Archive, e.Files...)
+		}
+	}
+	if tm, found := managed.TryGetMod(game, modID); found && !tm.Enabled() {
+		return managed.EnableMod(tm)
+	}
+	return nil
+}
+
+func rollBack(game config.GameDef, modID mods.ModID, entries []entry) (err error) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		switch e.Op {
+		case opInstall:
+			if util.FileExists(e.To) {
+				if err = os.Remove(e.To); err != nil {
+					return
+				}
+			}
+			files.RemoveFiles(game, modID, e.To)
+		case opUntrack:
+			files.SetFiles(game, e.Mod, e.From)
+			if e.Hash != "" {
+				files.SetHash(game, e.Mod, e.From, e.Hash)
+			}
+		case opReplace:
+			// The backup was there before this install and still holds the vanilla file, only the replaced file is
+			// put back
+			if err = moveBack(e); err != nil {
+				return
+			}
+		case opBackup:
+			if err = moveBack(e); err != nil {
+				return
+			}
+			files.RemoveBackups(game, e.To)
+		case opExtract:
+			_ = os.Remove(e.To)
+			files.RemoveBackups(game, e.To)
+		case opArchive:
+			if err = restoreEntries(e); err != nil {
+				return
+			}
+			files.RemoveArchiveFiles(game, modID, e.Archive, e.Files...)
+		}
+	}
+	if tm, found := managed.TryGetMod(game, modID); found && tm.Enabled() {
+		return managed.DisableMod(tm)
+	}
+	return nil
+}
+
+// moveBack undoes moving the game file e.From to e.To.
+func moveBack(e entry) error {
+	if !util.FileExists(e.To) {
+		return nil
+	}
+	if util.FileExists(e.From) {
+		// The move was cut short while copying, the game file is still intact
+		return os.Remove(e.To)
+	}
+	return util.MoveFile(e.To, e.From)
+}
+
+// restoreEntries puts back the entries of the archive e.From saved by Archive and deletes the ones the install added.
+func restoreEntries(e entry) error {
+	var (
+		saved = make(map[string]string, len(e.Replaced))
+		added []string
+	)
+	for _, f := range e.Replaced {
+		saved[f] = filepath.Join(e.To, f)
+	}
+	for _, f := range e.Files {
+		if _, found := saved[f]; !found {
+			added = append(added, f)
+		}
+	}
+	if len(saved) > 0 {
+		if err := archive.UpdateEntries(e.From, saved); err != nil {
+			return err
+		}
+	}
+	return archive.DeleteEntries(e.From, added)
+}
@@ -0,0 +1,214 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+const (
+	other mods.ModID = "other"
+	mod   mods.ModID = "mod"
+)
+
+type install struct {
+	game    config.GameDef
+	file    string
+	backup  string
+	modFile string
+	hash    string
+}
+
+// setup creates a game whose file is installed by other and backed up, and a mod with a file that replaces it.
+func setup(t *testing.T) *install {
+	var (
+		game = configtest.Game(t)
+		dir  = config.PWD
+		err  error
+	)
+	i := &install{
+		game:    game,
+		file:    filepath.Join(dir, "game", "data.txt"),
+		backup:  filepath.Join(dir, "backup", "data.txt"),
+		modFile: filepath.Join(dir, "mod", "data.txt"),
+	}
+	write(t, i.file, "other")
+	write(t, i.backup, "vanilla")
+	write(t, i.modFile, "mod")
+	if i.hash, err = util.HashFile(i.file); err != nil {
+		t.Fatal(err)
+	}
+	files.SetFiles(game, other, i.file)
+	files.SetHash(game, other, i.file, i.hash)
+	return i
+}
+
+// run makes the journaled changes Install makes when mod replaces other's file.
+func (i *install) run(t *testing.T) *Journal {
+	j, err := Begin(i.game, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Untrack(other, i.file); err != nil {
+		t.Fatal(err)
+	}
+	files.RemoveFiles(i.game, other, i.file)
+	if err = j.Replace(i.file); err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Install(i.modFile, i.file); err != nil {
+		t.Fatal(err)
+	}
+	if err = util.MoveFile(i.modFile, i.file); err != nil {
+		t.Fatal(err)
+	}
+	files.SetFiles(i.game, mod, i.file)
+	return j
+}
+
+func TestRollback(t *testing.T) {
+	i := setup(t)
+	if err := i.run(t).Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, i.file); s != "other" {
+		t.Errorf("game file = %q, want %q", s, "other")
+	}
+	if s := readFile(t, i.backup); s != "vanilla" {
+		t.Errorf("backup = %q, want %q", s, "vanilla")
+	}
+	if f := files.Files(i.game, other); !f.Contains(i.file) {
+		t.Errorf("%s is no longer tracked for %s", i.file, other)
+	}
+	if h, _ := files.Hash(i.game, other, i.file); h != i.hash {
+		t.Errorf("hash = %q, want %q", h, i.hash)
+	}
+	if f := files.Files(i.game, mod); f.Contains(i.file) {
+		t.Errorf("%s is still tracked for %s", i.file, mod)
+	}
+	if util.FileExists(dir()) {
+		t.Error("journal was not removed")
+	}
+}
+
+func TestRollbackArchive(t *testing.T) {
+	var (
+		i       = setup(t)
+		dir     = filepath.Dir(i.file)
+		arch    = filepath.Join(dir, "data.zip")
+		vanilla = filepath.Join(dir, "vanilla.txt")
+		modded  = filepath.Join(dir, "modded.txt")
+	)
+	write(t, vanilla, "vanilla")
+	write(t, modded, "mod")
+	if err := archive.ZipCreate(arch, map[string]string{"a/replaced.txt": vanilla, "kept.txt": vanilla}); err != nil {
+		t.Fatal(err)
+	}
+	j, err := Begin(i.game, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	injected := []string{filepath.Join("a", "replaced.txt"), "added.txt"}
+	if err = j.Archive(arch, "data.zip", injected); err != nil {
+		t.Fatal(err)
+	}
+	if err = archive.ZipUpdate(arch, map[string]string{"a/replaced.txt": modded, "added.txt": modded}); err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		entry string
+		want  string
+	}{
+		{"a/replaced.txt", "vanilla"},
+		{"kept.txt", "vanilla"},
+		{"added.txt", ""},
+	}
+	for _, tt := range tests {
+		found, err := archive.ZipContains(arch, tt.entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != (tt.want != "") {
+			t.Errorf("%s found = %v, want %v", tt.entry, found, !found)
+			continue
+		}
+		if !found {
+			continue
+		}
+		to := filepath.Join(dir, "out", tt.entry)
+		if err = archive.ZipExtract(arch, tt.entry, to); err != nil {
+			t.Fatal(err)
+		}
+		if s := readFile(t, to); s != tt.want {
+			t.Errorf("%s = %q, want %q", tt.entry, s, tt.want)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed bool
+		want      string
+		owner     mods.ModID
+	}{
+		{"interrupted", false, "other", other},
+		{"installed", true, "mod", mod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := setup(t)
+			j := i.run(t)
+			if tt.installed {
+				if err := j.Installed(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Crash
+			j.close()
+			files.SetFiles(i.game, other, i.file)
+			files.RemoveFiles(i.game, mod, i.file)
+
+			if err := Recover(); err != nil {
+				t.Fatal(err)
+			}
+			if s := readFile(t, i.file); s != tt.want {
+				t.Errorf("game file = %q, want %q", s, tt.want)
+			}
+			if owner, _ := files.HasFile(i.game, i.file); owner != tt.owner {
+				t.Errorf("owner = %q, want %q", owner, tt.owner)
+			}
+			if util.FileExists(dir()) {
+				t.Error("journal was not removed")
+			}
+		})
+	}
+}
+
+func write(t *testing.T, file, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, file string) string {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
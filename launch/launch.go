@@ -0,0 +1,150 @@
+package launch
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+)
+
+// SteamProfile is the name of the profile every game has that asks Steam to run it.
+const SteamProfile = "Steam"
+
+// Profiles returns the ways game can be launched: through Steam, followed by the profiles the game's definition
+// provides and then those the user added.
+func Profiles(game config.GameDef) []*config.LaunchProfile {
+	profiles := []*config.LaunchProfile{{Name: SteamProfile, Kind: config.LaunchSteam}}
+	profiles = append(profiles, game.LaunchProfiles()...)
+	return append(profiles, config.Get().LaunchConfig(game).Profiles...)
+}
+
+// Find returns the launch profile of game called name.
+func Find(game config.GameDef, name string) (*config.LaunchProfile, error) {
+	for _, p := range Profiles(game) {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("launch profile [%s] not found", name)
+}
+
+// Default returns the profile the user chose to launch game with, Steam when none was chosen or it no longer exists.
+func Default(game config.GameDef) *config.LaunchProfile {
+	if name := config.Get().LaunchConfig(game).Default; name != "" {
+		if p, err := Find(game, name); err == nil {
+			return p
+		}
+	}
+	return Profiles(game)[0]
+}
+
+// Launch starts game using p once the pre-launch checks pass. It does not wait for the game to exit.
+func Launch(game config.GameDef, p *config.LaunchProfile) error {
+	if err := check(game); err != nil {
+		return err
+	}
+	cmd, err := command(game, p)
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("failed to launch %s with %s: %v", game.Name(), p.Name, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// check refuses to launch while an action is changing the game's files, or while they have problems when the user
+// asked for them to be verified first.
+func check(game config.GameDef) error {
+	if actions.Running() {
+		return errors.New("the game cannot be launched while a mod is being installed or uninstalled")
+	}
+	if config.Get().LaunchConfig(game).VerifyFirst {
+		issues, err := files.VerifyAll(game)
+		if err != nil {
+			return err
+		}
+		if len(issues) > 0 {
+			return fmt.Errorf("the game was not launched because verify found %d problem(s) with the installed mods", len(issues))
+		}
+	}
+	return nil
+}
+
+func command(game config.GameDef, p *config.LaunchProfile) (cmd *exec.Cmd, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	var (
+		gameDir, _ = config.Get().GetDir(game, config.GameDirKind)
+		env        = append(os.Environ(), "GAME_DIR="+gameDir, "STEAM_ID="+string(game.SteamID()))
+		args       = make([]string, len(p.Args))
+	)
+	for k, v := range p.Env {
+		env = append(env, k+"="+v)
+	}
+	for i, a := range p.Args {
+		args[i] = os.Expand(a, func(k string) string { return lookup(env, k) })
+	}
+
+	switch p.Kind {
+	case config.LaunchSteam:
+		uri := fmt.Sprintf("steam://rungameid/%s", game.SteamID())
+		if len(args) > 0 {
+			uri = fmt.Sprintf("steam://run/%s//%s/", game.SteamID(), url.PathEscape(strings.Join(args, " ")))
+		}
+		cmd = open(uri)
+	case config.LaunchExecutable:
+		path := p.Path
+		if !filepath.IsAbs(path) {
+			if gameDir == "" {
+				return nil, fmt.Errorf("the directory of %s is not configured", game.Name())
+			}
+			path = filepath.Join(gameDir, path)
+		}
+		cmd = exec.Command(path, args...)
+		cmd.Dir = filepath.Dir(path)
+	case config.LaunchCommand:
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", p.Command)
+		} else {
+			cmd = exec.Command("sh", "-c", p.Command)
+		}
+		cmd.Dir = gameDir
+	}
+	if p.WorkingDir != "" {
+		cmd.Dir = p.WorkingDir
+	}
+	cmd.Env = env
+	return
+}
+
+// open opens uri with the system's handler for it.
+func open(uri string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("explorer", uri)
+	case "darwin":
+		return exec.Command("open", uri)
+	}
+	return exec.Command("xdg-open", uri)
+}
+
+// lookup returns the last value of the variable k in env, as that is the one a started process sees.
+func lookup(env []string, k string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if strings.HasPrefix(env[i], k+"=") {
+			return strings.TrimPrefix(env[i], k+"=")
+		}
+	}
+	return ""
+}
@@ -14,8 +14,13 @@ import (
 	"github.com/kiamev/moogle-mod-manager/config/secrets"
 	"github.com/kiamev/moogle-mod-manager/discover/repo"
 	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/journal"
 	"github.com/kiamev/moogle-mod-manager/mods/managed"
 	"github.com/kiamev/moogle-mod-manager/mods/managed/authored"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/conflictrules"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/loadorder"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+	"github.com/kiamev/moogle-mod-manager/prompt"
 	config_installer "github.com/kiamev/moogle-mod-manager/ui/config-installer"
 	"github.com/kiamev/moogle-mod-manager/ui/configure"
 	"github.com/kiamev/moogle-mod-manager/ui/discover"
@@ -23,11 +28,13 @@ import (
 	"github.com/kiamev/moogle-mod-manager/ui/local"
 	"github.com/kiamev/moogle-mod-manager/ui/menu"
 	mod_author "github.com/kiamev/moogle-mod-manager/ui/mod-author"
+	ui_prompt "github.com/kiamev/moogle-mod-manager/ui/prompt"
 	"github.com/kiamev/moogle-mod-manager/ui/secret"
 	"github.com/kiamev/moogle-mod-manager/ui/state"
 	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
 	"github.com/kiamev/moogle-mod-manager/ui/util"
 	"github.com/kiamev/moogle-mod-manager/ui/util/resources"
+	"github.com/kiamev/moogle-mod-manager/ui/util/working"
 )
 
 func main() {
@@ -67,6 +74,8 @@ func main() {
 	state.RegisterScreen(state.LocalMods, local.New())
 	state.RegisterScreen(state.DiscoverMods, discover.New())
 	state.RegisterScreen(state.ConfigInstaller, config_installer.New())
+	prompt.Set(ui_prompt.New())
+	browser.SetProgress(working.SetProgress)
 
 	state.ShowScreen(state.None)
 	if config.Get().FirstTime {
@@ -137,6 +146,19 @@ func initialize() {
 	if err = authored.Initialize(); err != nil {
 		util.ShowErrorLong(err)
 	}
+	if err = profiles.Initialize(); err != nil {
+		util.ShowErrorLong(err)
+	}
+	if err = loadorder.Initialize(); err != nil {
+		util.ShowErrorLong(err)
+	}
+	if err = conflictrules.Initialize(); err != nil {
+		util.ShowErrorLong(err)
+	}
+	// Finish or undo any install that was interrupted before anything else touches the game files
+	if err = journal.Recover(); err != nil {
+		util.ShowErrorLong(err)
+	}
 
 	configs.InitializeGames(config.GameDefs())
 	resources.Initialize(config.GameDefs())
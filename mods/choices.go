@@ -0,0 +1,140 @@
+package mods
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SelectedChoices maps a configuration's name to the names of the choices selected for it.
+type SelectedChoices map[string][]string
+
+// RootConfiguration returns the configuration a mod's configuration chain starts from.
+func (m *Mod) RootConfiguration() (*Configuration, error) {
+	if len(m.Configurations) == 0 || len(m.Configurations[0].Choices) == 0 {
+		return nil, fmt.Errorf("no configurations for %s", m.Name)
+	}
+	for _, c := range m.Configurations {
+		if c.Root {
+			return c, nil
+		}
+	}
+	return nil, errors.New("could not find root configuration")
+}
+
+func (m *Mod) GetConfiguration(name string) *Configuration {
+	for _, c := range m.Configurations {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *Configuration) GetChoice(name string) *Choice {
+	for _, ch := range c.Choices {
+		if ch.Name == name {
+			return ch
+		}
+	}
+	return nil
+}
+
+// SelectedChoices names the configuration each of the choices belongs to.
+func (m *Mod) SelectedChoices(choices []*Choice) SelectedChoices {
+	if len(choices) == 0 {
+		return nil
+	}
+	sc := make(SelectedChoices)
+	for _, c := range m.Configurations {
+		for _, ch := range c.Choices {
+			for _, selected := range choices {
+				if ch == selected {
+					sc[c.Name] = append(sc[c.Name], ch.Name)
+				}
+			}
+		}
+	}
+	return sc
+}
+
+func (c SelectedChoices) Equals(o SelectedChoices) bool {
+	if len(c) != len(o) {
+		return false
+	}
+	for k, v := range c {
+		ov, found := o[k]
+		if !found || len(v) != len(ov) {
+			return false
+		}
+		for i := range v {
+			if v[i] != ov[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// DownloadFilesForChoices combines the mod's AlwaysDownload with the DownloadFiles of the selected choices.
+func (m *Mod) DownloadFilesForChoices(choices []*Choice) []*DownloadFiles {
+	var (
+		l  = make(map[string]*DownloadFiles)
+		df *DownloadFiles
+	)
+	for _, df = range m.AlwaysDownload {
+		l[df.DownloadName] = df
+	}
+	for _, c := range choices {
+		df = c.DownloadFiles
+		if df != nil && df.DownloadName != "" {
+			if len(df.Dirs) > 0 || len(df.Files) > 0 {
+				if to, found := l[df.DownloadName]; !found {
+					l[df.DownloadName] = df
+				} else {
+					l[df.DownloadName] = mergeDownloadFiles(to, df)
+				}
+			}
+		}
+	}
+	result := make([]*DownloadFiles, 0, len(l))
+	for _, df = range l {
+		result = append(result, df)
+	}
+	return result
+}
+
+func mergeDownloadFiles(df1 *DownloadFiles, df2 *DownloadFiles) *DownloadFiles {
+	var (
+		m     = make(map[string]bool)
+		dirs  = make([]*ModDir, 0, len(df1.Dirs)+len(df2.Dirs))
+		files = make([]*ModFile, 0, len(df1.Files)+len(df2.Files))
+	)
+	for _, d := range df2.Dirs {
+		m[d.To] = true
+		dirs = append(dirs, d)
+	}
+	for _, d := range df1.Dirs {
+		if !m[d.To] {
+			dirs = append(dirs, d)
+		}
+	}
+
+	m = make(map[string]bool)
+	for _, f := range df2.Files {
+		m[f.To] = true
+		files = append(files, f)
+	}
+	for _, f := range df1.Files {
+		if !m[f.To] {
+			files = append(files, f)
+		}
+	}
+	return &DownloadFiles{
+		DownloadName:           df1.DownloadName,
+		Dirs:                   dirs,
+		Files:                  files,
+		Include:                append(append([]string(nil), df1.Include...), df2.Include...),
+		Exclude:                append(append([]string(nil), df1.Exclude...), df2.Exclude...),
+		ReplaceDefaultExcludes: df1.ReplaceDefaultExcludes || df2.ReplaceDefaultExcludes,
+	}
+}
@@ -3,9 +3,22 @@ package mods
 type ModCompatibility struct {
 	Requires []*ModCompat `json:"Require,omitempty" xml:"Requires,omitempty"`
 	Forbids  []*ModCompat `json:"Forbid,omitempty" xml:"Forbids,omitempty"`
-	// OrderConstraints []ModCompat `json:"OrderConstraint"`
+	// OrderConstraints declare that this mod loads Before or After other mods. The mod that loads last wins file conflicts.
+	OrderConstraints []*ModCompat `json:"OrderConstraint,omitempty" xml:"OrderConstraints,omitempty"`
 }
 
 func (c *ModCompatibility) HasItems() bool {
-	return c != nil && (len(c.Requires) > 0 || len(c.Forbids) > 0)
+	return c != nil && (len(c.Requires) > 0 || len(c.Forbids) > 0 || len(c.OrderConstraints) > 0)
+}
+
+// LoadOrder returns how this mod declared it loads relative to other.
+func (c *ModCompatibility) LoadOrder(other ModID) ModCompatOrder {
+	if c != nil {
+		for _, oc := range c.OrderConstraints {
+			if oc.ModID() == other {
+				return oc.Order
+			}
+		}
+	}
+	return None
 }
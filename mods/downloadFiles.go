@@ -1,10 +1,75 @@
 package mods
 
+import (
+	"fmt"
+
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// DefaultExcludes are the files that are not extracted or installed unless a DownloadFiles includes them or lists
+// them by path in its Files.
+var DefaultExcludes = []string{"readme*", "license*", ".git*", "__macosx*", ".ds_store*"}
+
 type DownloadFiles struct {
 	DownloadName string `json:"DownloadName" xml:"DownloadName"`
 	// IsInstallAll is used by nexus mods when a mod.xml is not used
+	// Files listed by path rather than by pattern are always installed, the include and exclude patterns do not
+	// apply to them
 	Files []*ModFile `json:"File,omitempty" xml:"Files,omitempty"`
 	Dirs  []*ModDir  `json:"Dir,omitempty" xml:"Dirs,omitempty"`
+	// Include are glob patterns of files to keep even when an exclude pattern matches them
+	Include []string `json:"Include,omitempty" xml:"Include,omitempty"`
+	// Exclude are glob patterns of files to leave out, added to DefaultExcludes
+	Exclude []string `json:"Exclude,omitempty" xml:"Exclude,omitempty"`
+	// ReplaceDefaultExcludes makes Exclude replace DefaultExcludes instead of adding to them
+	ReplaceDefaultExcludes bool `json:"ReplaceDefaultExcludes,omitempty" xml:"ReplaceDefaultExcludes,omitempty"`
+}
+
+// Skip reports whether the file at path, relative to the root of its download, is left out by the include and
+// exclude patterns.
+func (f *DownloadFiles) Skip(path string) bool {
+	for _, p := range f.Include {
+		if util.MatchGlob(p, path) {
+			return false
+		}
+	}
+	if !f.ReplaceDefaultExcludes {
+		for _, p := range DefaultExcludes {
+			if util.MatchGlob(p, path) {
+				return true
+			}
+		}
+	}
+	for _, p := range f.Exclude {
+		if util.MatchGlob(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePatterns returns an error for the first include, exclude or File and Dir From pattern that is not valid.
+func (f *DownloadFiles) ValidatePatterns() error {
+	for _, p := range append(append([]string(nil), f.Include...), f.Exclude...) {
+		if !util.ValidGlob(p) {
+			return fmt.Errorf("pattern [%s] is not a valid glob", p)
+		}
+	}
+	var froms []string
+	for _, file := range f.Files {
+		froms = append(froms, file.From)
+	}
+	for _, d := range f.Dirs {
+		froms = append(froms, d.From)
+	}
+	for _, from := range froms {
+		if IsPattern(from) {
+			if _, err := CompilePattern(from); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (f *DownloadFiles) IsEmpty() bool {
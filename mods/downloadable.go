@@ -1,9 +1,20 @@
 package mods
 
 import (
+	"errors"
 	"fmt"
 	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/util"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrIntegrity is returned when a downloaded file does not have the size or SHA-256 its Download lists.
+	ErrIntegrity  = errors.New("downloaded file is corrupt")
+	sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
 )
 
 type (
@@ -11,6 +22,10 @@ type (
 	Download        struct {
 		Name    string `json:"Name" xml:"Name"`
 		Version string `json:"Version" xml:"Version"`
+		// Sha256 and Size are optional and checked before the downloaded file is extracted, whichever source it is
+		// downloaded from
+		Sha256 string `json:"Sha256,omitempty" xml:"Sha256,omitempty"`
+		Size   int64  `json:"Size,omitempty" xml:"Size,omitempty"`
 
 		Hosted      *HostedDownloadable      `json:"Hosted,omitempty" xml:"Hosted,omitempty"`
 		Nexus       *NexusDownloadable       `json:"Nexus,omitempty" xml:"Nexus,omitempty"`
@@ -49,6 +64,30 @@ func (d Download) FileName() (string, error) {
 	return "", fmt.Errorf("no file name specified for %s", d.Name)
 }
 
+// Verify checks file against the download's Sha256 and Size, empty or zero values are not checked. The returned error
+// wraps ErrIntegrity when file does not match.
+func (d Download) Verify(file string) error {
+	if d.Size != 0 {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if fi.Size() != d.Size {
+			return fmt.Errorf("%w: %s is %d bytes but should be %d", ErrIntegrity, filepath.Base(file), fi.Size(), d.Size)
+		}
+	}
+	if d.Sha256 != "" {
+		h, err := util.HashFile(file)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(h, d.Sha256) {
+			return fmt.Errorf("%w: %s has SHA-256 %s but should have %s", ErrIntegrity, filepath.Base(file), h, d.Sha256)
+		}
+	}
+	return nil
+}
+
 func (l *ArchiveLocation) ExtractDir(fileName string) string {
 	s := config.PWD
 	if l != nil {
@@ -0,0 +1,138 @@
+package conflictrules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+const file = "conflictrules.json"
+
+type (
+	Kind string
+	// Rule settles file conflicts without asking. Rules are tried in order and the first that applies decides.
+	Rule struct {
+		Kind Kind       `json:"kind"`
+		Mod  mods.ModID `json:"mod"`
+		// Other is the mod that Mod wins over for a Wins rule
+		Other mods.ModID `json:"other,omitempty"`
+		// Pattern is the glob of the files a Prefer rule is for, relative to the game's directory
+		Pattern string `json:"pattern,omitempty"`
+	}
+)
+
+const (
+	// Wins makes Mod win every conflict with Other
+	Wins Kind = "Wins"
+	// Prefer makes Mod win the conflicts over the files matching Pattern
+	Prefer Kind = "Prefer"
+	// Keep never lets another mod overwrite a file owned by Mod
+	Keep Kind = "Keep"
+)
+
+var (
+	Kinds = []Kind{Wins, Prefer, Keep}
+	// lookup holds each game's rules
+	lookup = make(map[config.GameID][]*Rule)
+)
+
+func Initialize() error {
+	if err := util.LoadFromFile(filepath.Join(config.PWD, file), &lookup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load the conflict rules: %v", err)
+	}
+	return nil
+}
+
+// Get returns a copy of the game's rules.
+func Get(game config.GameDef) []*Rule {
+	rules := make([]*Rule, 0, len(lookup[game.ID()]))
+	for _, r := range lookup[game.ID()] {
+		c := *r
+		rules = append(rules, &c)
+	}
+	return rules
+}
+
+// Set replaces the game's rules.
+func Set(game config.GameDef, rules []*Rule) error {
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	lookup[game.ID()] = rules
+	return save()
+}
+
+// Winner decides which of mod, the mod being installed, and owner keeps the file at path, relative to the game's
+// directory, using the first of the game's rules that applies. decided is false when no rule does.
+func Winner(game config.GameDef, path string, mod *mods.Mod, owner *mods.Mod) (winner *mods.Mod, decided bool) {
+	for _, r := range lookup[game.ID()] {
+		if id, ok := r.winner(path, mod.ID(), owner.ID()); ok {
+			if id == mod.ID() {
+				return mod, true
+			}
+			return owner, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Rule) winner(path string, mod mods.ModID, owner mods.ModID) (mods.ModID, bool) {
+	switch r.Kind {
+	case Wins:
+		if (r.Mod == mod && r.Other == owner) || (r.Mod == owner && r.Other == mod) {
+			return r.Mod, true
+		}
+	case Prefer:
+		if (r.Mod == mod || r.Mod == owner) && util.MatchGlob(r.Pattern, filepath.ToSlash(path)) {
+			return r.Mod, true
+		}
+	case Keep:
+		if r.Mod == owner {
+			return owner, true
+		}
+	}
+	return "", false
+}
+
+func (r *Rule) Validate() error {
+	if r.Mod == "" {
+		return errors.New("a conflict rule's mod is required")
+	}
+	switch r.Kind {
+	case Wins:
+		if r.Other == "" || r.Other == r.Mod {
+			return fmt.Errorf("[%s] must win over another mod", r.Mod)
+		}
+	case Prefer:
+		if !util.ValidGlob(r.Pattern) {
+			return fmt.Errorf("[%s]'s pattern [%s] is not a valid glob", r.Mod, r.Pattern)
+		}
+	case Keep:
+	default:
+		return fmt.Errorf("unknown conflict rule %s", r.Kind)
+	}
+	return nil
+}
+
+func (r *Rule) String() string {
+	switch r.Kind {
+	case Wins:
+		return fmt.Sprintf("%s always wins over %s", r.Mod, r.Other)
+	case Prefer:
+		return fmt.Sprintf("%s wins files matching %s", r.Mod, r.Pattern)
+	case Keep:
+		return fmt.Sprintf("files owned by %s are never overwritten", r.Mod)
+	}
+	return string(r.Kind)
+}
+
+func save() error {
+	return util.SaveToFile(filepath.Join(config.PWD, file), lookup)
+}
@@ -0,0 +1,60 @@
+package conflictrules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+func TestRuleWinner(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		path    string
+		mod     mods.ModID
+		owner   mods.ModID
+		want    mods.ModID
+		decided bool
+	}{
+		{"wins installing", Rule{Kind: Wins, Mod: "a", Other: "b"}, "x.txt", "a", "b", "a", true},
+		{"wins owning", Rule{Kind: Wins, Mod: "a", Other: "b"}, "x.txt", "b", "a", "a", true},
+		{"wins other pair", Rule{Kind: Wins, Mod: "a", Other: "b"}, "x.txt", "a", "c", "", false},
+		{"prefer installing", Rule{Kind: Prefer, Mod: "a", Pattern: "*.png"}, filepath.Join("img", "x.png"), "a", "b", "a", true},
+		{"prefer owning", Rule{Kind: Prefer, Mod: "a", Pattern: "img/*.png"}, filepath.Join("img", "x.png"), "b", "a", "a", true},
+		{"prefer other files", Rule{Kind: Prefer, Mod: "a", Pattern: "img/*.png"}, filepath.Join("snd", "x.png"), "a", "b", "", false},
+		{"prefer other mods", Rule{Kind: Prefer, Mod: "a", Pattern: "*.png"}, "x.png", "b", "c", "", false},
+		{"keep owning", Rule{Kind: Keep, Mod: "a"}, "x.txt", "b", "a", "a", true},
+		{"keep installing", Rule{Kind: Keep, Mod: "a"}, "x.txt", "a", "b", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, decided := tt.rule.winner(tt.path, tt.mod, tt.owner)
+			if decided != tt.decided || got != tt.want {
+				t.Errorf("winner() = %q, %v, want %q, %v", got, decided, tt.want, tt.decided)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		wantErr bool
+	}{
+		{Rule{Kind: Wins, Mod: "a", Other: "b"}, false},
+		{Rule{Kind: Wins, Mod: "a"}, true},
+		{Rule{Kind: Wins, Mod: "a", Other: "a"}, true},
+		{Rule{Kind: Prefer, Mod: "a", Pattern: "**/*.png"}, false},
+		{Rule{Kind: Prefer, Mod: "a"}, true},
+		{Rule{Kind: Prefer, Mod: "a", Pattern: "[a-"}, true},
+		{Rule{Kind: Keep, Mod: "a"}, false},
+		{Rule{Kind: Keep}, true},
+		{Rule{Kind: "Other", Mod: "a"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%v.Validate() error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+		}
+	}
+}
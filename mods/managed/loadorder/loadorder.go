@@ -0,0 +1,163 @@
+package loadorder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+const file = "loadorder.json"
+
+// lookup holds each game's priority list. Mods later in a list load later and win conflicts over the mods before them.
+var lookup = make(map[config.GameID][]mods.ModID)
+
+func Initialize() error {
+	if err := util.LoadFromFile(filepath.Join(config.PWD, file), &lookup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load the load order: %v", err)
+	}
+	return nil
+}
+
+// Get returns a copy of the game's priority list, lowest priority first.
+func Get(game config.GameDef) []mods.ModID {
+	return append([]mods.ModID(nil), lookup[game.ID()]...)
+}
+
+// Set replaces the game's priority list.
+func Set(game config.GameDef, order []mods.ModID) error {
+	var (
+		seen   = make(map[mods.ModID]bool)
+		unique = make([]mods.ModID, 0, len(order))
+	)
+	for _, id := range order {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	lookup[game.ID()] = unique
+	return save()
+}
+
+// Winner decides which of mod and other keeps the files they both install.
+// Order constraints declared by either mod are used first, then the game's priority list.
+// decided is false when neither has anything to say about the pair.
+func Winner(game config.GameDef, mod *mods.Mod, other *mods.Mod) (winner *mods.Mod, decided bool) {
+	var modWins, otherWins bool
+	switch mod.ModCompatibility.LoadOrder(other.ID()) {
+	case mods.After:
+		modWins = true
+	case mods.Before:
+		otherWins = true
+	}
+	switch other.ModCompatibility.LoadOrder(mod.ID()) {
+	case mods.After:
+		otherWins = true
+	case mods.Before:
+		modWins = true
+	}
+	if modWins != otherWins {
+		if modWins {
+			return mod, true
+		}
+		return other, true
+	}
+
+	order := lookup[game.ID()]
+	i, j := indexOf(order, mod.ID()), indexOf(order, other.ID())
+	if i == -1 || j == -1 {
+		return nil, false
+	}
+	if i > j {
+		return mod, true
+	}
+	return other, true
+}
+
+// Place adds id to the game's priority list after the mods it won over and before the mods it lost to,
+// so the same conflicts are settled without asking next time. A mod that id both won over and lost to is left out
+// as its files were chosen one by one.
+func Place(game config.GameDef, id mods.ModID, won []mods.ModID, lost []mods.ModID) error {
+	if won, lost = settled(id, won, lost); len(won) == 0 && len(lost) == 0 {
+		return nil
+	}
+	order := lookup[game.ID()]
+	if indexOf(order, id) == -1 {
+		// Mods that are not ordered yet were installed before this one
+		for _, o := range append(append([]mods.ModID(nil), won...), lost...) {
+			if indexOf(order, o) == -1 {
+				order = append(order, o)
+			}
+		}
+		i := len(order)
+		for _, o := range lost {
+			if j := indexOf(order, o); j < i {
+				i = j
+			}
+		}
+		order = insert(order, i, id)
+	} else {
+		for _, o := range won {
+			if indexOf(order, o) == -1 {
+				order = insert(order, indexOf(order, id), o)
+			}
+		}
+		for _, o := range lost {
+			if indexOf(order, o) == -1 {
+				order = append(order, o)
+			}
+		}
+	}
+	lookup[game.ID()] = order
+	return save()
+}
+
+// settled returns won and lost without duplicates, id and the mods found in both.
+func settled(id mods.ModID, won []mods.ModID, lost []mods.ModID) (w []mods.ModID, l []mods.ModID) {
+	var (
+		inWon  = make(map[mods.ModID]bool)
+		inLost = make(map[mods.ModID]bool)
+	)
+	for _, o := range won {
+		inWon[o] = true
+	}
+	for _, o := range lost {
+		inLost[o] = true
+	}
+	for _, o := range won {
+		if o != id && !inLost[o] && indexOf(w, o) == -1 {
+			w = append(w, o)
+		}
+	}
+	for _, o := range lost {
+		if o != id && !inWon[o] && indexOf(l, o) == -1 {
+			l = append(l, o)
+		}
+	}
+	return
+}
+
+func indexOf(order []mods.ModID, id mods.ModID) int {
+	for i, o := range order {
+		if o == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func insert(order []mods.ModID, i int, id mods.ModID) []mods.ModID {
+	order = append(order, "")
+	copy(order[i+1:], order[i:])
+	order[i] = id
+	return order
+}
+
+func save() error {
+	return util.SaveToFile(filepath.Join(config.PWD, file), lookup)
+}
@@ -0,0 +1,88 @@
+package loadorder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+func newMod(id mods.ModID, constraints ...*mods.ModCompat) *mods.Mod {
+	return mods.NewMod(&mods.ModDef{
+		ModID:            id,
+		ModCompatibility: &mods.ModCompatibility{OrderConstraints: constraints},
+	})
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []mods.ModID
+		id    mods.ModID
+		won   []mods.ModID
+		lost  []mods.ModID
+		want  []mods.ModID
+	}{
+		{"first", nil, "c", []mods.ModID{"a"}, []mods.ModID{"b"}, []mods.ModID{"a", "c", "b"}},
+		{"after winners", []mods.ModID{"a", "b"}, "c", []mods.ModID{"a", "b"}, nil, []mods.ModID{"a", "b", "c"}},
+		{"before losers", []mods.ModID{"a", "b"}, "c", nil, []mods.ModID{"a"}, []mods.ModID{"c", "a", "b"}},
+		{"already placed", []mods.ModID{"c", "a"}, "c", []mods.ModID{"b"}, []mods.ModID{"d"}, []mods.ModID{"b", "c", "a", "d"}},
+		{"duplicates", nil, "c", []mods.ModID{"a", "a"}, []mods.ModID{"b", "b"}, []mods.ModID{"a", "c", "b"}},
+		{"won and lost", []mods.ModID{"a"}, "c", []mods.ModID{"a", "b"}, []mods.ModID{"a"}, []mods.ModID{"a", "b", "c"}},
+		{"only contradicting", []mods.ModID{"a"}, "c", []mods.ModID{"a"}, []mods.ModID{"a"}, []mods.ModID{"a"}},
+		{"itself", nil, "c", []mods.ModID{"c", "a"}, nil, []mods.ModID{"a", "c"}},
+	}
+	game := configtest.Game(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Set(game, tt.order); err != nil {
+				t.Fatal(err)
+			}
+			if err := Place(game, tt.id, tt.won, tt.lost); err != nil {
+				t.Fatal(err)
+			}
+			if got := Get(game); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Place() order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWinner(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []mods.ModID
+		mod     *mods.Mod
+		other   *mods.Mod
+		want    mods.ModID
+		decided bool
+	}{
+		{"unordered", nil, newMod("a"), newMod("b"), "", false},
+		{"one unordered", []mods.ModID{"a"}, newMod("a"), newMod("b"), "", false},
+		{"later wins", []mods.ModID{"b", "a"}, newMod("a"), newMod("b"), "a", true},
+		{"earlier loses", []mods.ModID{"a", "b"}, newMod("a"), newMod("b"), "b", true},
+		{"mod after", []mods.ModID{"a", "b"}, newMod("a", &mods.ModCompat{ID: "b", Order: mods.After}), newMod("b"), "a", true},
+		{"mod before", []mods.ModID{"b", "a"}, newMod("a", &mods.ModCompat{ID: "b", Order: mods.Before}), newMod("b"), "b", true},
+		{"other after", nil, newMod("a"), newMod("b", &mods.ModCompat{ID: "a", Order: mods.After}), "b", true},
+		{"other before", nil, newMod("a"), newMod("b", &mods.ModCompat{ID: "a", Order: mods.Before}), "a", true},
+		{"contradicting falls back to order", []mods.ModID{"b", "a"},
+			newMod("a", &mods.ModCompat{ID: "b", Order: mods.After}),
+			newMod("b", &mods.ModCompat{ID: "a", Order: mods.After}), "a", true},
+	}
+	game := configtest.Game(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Set(game, tt.order); err != nil {
+				t.Fatal(err)
+			}
+			w, decided := Winner(game, tt.mod, tt.other)
+			if decided != tt.decided {
+				t.Fatalf("Winner() decided = %v, want %v", decided, tt.decided)
+			}
+			if decided && w.ID() != tt.want {
+				t.Errorf("Winner() = %s, want %s", w.ID(), tt.want)
+			}
+		})
+	}
+}
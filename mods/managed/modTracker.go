@@ -15,7 +15,6 @@ import (
 	"github.com/kiamev/moogle-mod-manager/discover/remote/curseforge"
 	"github.com/kiamev/moogle-mod-manager/discover/remote/nexus"
 	"github.com/kiamev/moogle-mod-manager/mods"
-	"github.com/kiamev/moogle-mod-manager/ui/state"
 	"github.com/kiamev/moogle-mod-manager/util"
 )
 
@@ -106,7 +105,7 @@ func AddMod(game config.GameDef, mod *mods.Mod) (tm mods.TrackedMod, err error)
 	if tm, found = lookup.GetModByID(game, mod.ID()); found {
 		return
 	}
-	tm = mods.NewTrackerMod(mod, state.CurrentGame)
+	tm = mods.NewTrackerMod(mod, game)
 	err = addMod(game, tm)
 	return
 }
@@ -143,6 +142,19 @@ func EnableMod(tm mods.TrackedMod) error {
 	return save()
 }
 
+// ApplyUpdate replaces the mod's definition with the newer one found by CheckForUpdates.
+func ApplyUpdate(tm mods.TrackedMod) error {
+	if tm.UpdatedMod() == nil {
+		return nil
+	}
+	tm.SetMod(tm.UpdatedMod())
+	tm.SetUpdatedMod(nil)
+	if err := saveMoogle(tm); err != nil {
+		return err
+	}
+	return save()
+}
+
 func GetEnabledMods(game config.GameDef) (result []mods.TrackedMod) {
 	for _, tm := range lookup.GetMods(game) {
 		if tm.Enabled() {
@@ -0,0 +1,156 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+const file = "profiles.json"
+
+type (
+	Profile struct {
+		Name string                              `json:"name"`
+		Mods map[mods.ModID]mods.SelectedChoices `json:"mods"`
+		// Winners maps an installed file to the mod that won the conflict over it
+		Winners map[string]mods.ModID `json:"winners,omitempty"`
+	}
+	gameProfiles struct {
+		Active   string              `json:"active,omitempty"`
+		Profiles map[string]*Profile `json:"profiles"`
+		// Winners holds the outcome of every conflict resolved for the installed mods. The files a mod won are
+		// forgotten when it is uninstalled.
+		Winners map[string]mods.ModID `json:"winners,omitempty"`
+	}
+	profiles struct {
+		Games map[config.GameID]*gameProfiles `json:"games"`
+	}
+)
+
+var lookup = &profiles{Games: make(map[config.GameID]*gameProfiles)}
+
+func Initialize() error {
+	if err := util.LoadFromFile(filepath.Join(config.PWD, file), lookup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load profiles: %v", err)
+	}
+	return nil
+}
+
+func forGame(game config.GameDef) *gameProfiles {
+	gp, ok := lookup.Games[game.ID()]
+	if !ok {
+		gp = &gameProfiles{
+			Profiles: make(map[string]*Profile),
+			Winners:  make(map[string]mods.ModID),
+		}
+		lookup.Games[game.ID()] = gp
+	}
+	if gp.Profiles == nil {
+		gp.Profiles = make(map[string]*Profile)
+	}
+	if gp.Winners == nil {
+		gp.Winners = make(map[string]mods.ModID)
+	}
+	return gp
+}
+
+func Names(game config.GameDef) []string {
+	var (
+		gp    = forGame(game)
+		names = make([]string, 0, len(gp.Profiles))
+	)
+	for n := range gp.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Get(game config.GameDef, name string) (p *Profile, found bool) {
+	p, found = forGame(game).Profiles[name]
+	return
+}
+
+func Active(game config.GameDef) string {
+	return forGame(game).Active
+}
+
+func SetActive(game config.GameDef, name string) error {
+	forGame(game).Active = name
+	return save()
+}
+
+// Save records the game's enabled mods, their configuration choices and the conflicts they won as the named profile.
+func Save(game config.GameDef, name string) (*Profile, error) {
+	if name == "" {
+		return nil, errors.New("profile name is required")
+	}
+	var (
+		gp = forGame(game)
+		p  = &Profile{
+			Name:    name,
+			Mods:    make(map[mods.ModID]mods.SelectedChoices),
+			Winners: make(map[string]mods.ModID),
+		}
+	)
+	for _, tm := range managed.GetEnabledMods(game) {
+		p.Mods[tm.ID()] = tm.Choices()
+	}
+	for f, id := range gp.Winners {
+		if _, ok := p.Mods[id]; ok {
+			p.Winners[f] = id
+		}
+	}
+	gp.Profiles[name] = p
+	gp.Active = name
+	return p, save()
+}
+
+func Delete(game config.GameDef, name string) error {
+	gp := forGame(game)
+	delete(gp.Profiles, name)
+	if gp.Active == name {
+		gp.Active = ""
+	}
+	return save()
+}
+
+// SetWinners records which mod won each of the resolved conflicts.
+func SetWinners(game config.GameDef, winners map[string]mods.ModID) error {
+	gp := forGame(game)
+	for f, id := range winners {
+		gp.Winners[f] = id
+	}
+	return save()
+}
+
+// RemoveWinners forgets the conflicts won by the mods, for when they are uninstalled. Without mods every conflict of
+// the game is forgotten.
+func RemoveWinners(game config.GameDef, modIDs ...mods.ModID) error {
+	gp := forGame(game)
+	if len(modIDs) == 0 {
+		gp.Winners = make(map[string]mods.ModID)
+		return save()
+	}
+	remove := make(map[mods.ModID]bool, len(modIDs))
+	for _, id := range modIDs {
+		remove[id] = true
+	}
+	for f, id := range gp.Winners {
+		if remove[id] {
+			delete(gp.Winners, f)
+		}
+	}
+	return save()
+}
+
+func save() error {
+	return util.SaveToFile(filepath.Join(config.PWD, file), lookup)
+}
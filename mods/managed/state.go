@@ -0,0 +1,56 @@
+package managed
+
+import (
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+// ModState is what the tracker records about one of a game's mods.
+type ModState struct {
+	Mod     *mods.Mod
+	Enabled bool
+	Choices mods.SelectedChoices
+}
+
+// ModStates returns the state of every mod tracked for game.
+func ModStates(game config.GameDef) (states []*ModState) {
+	for _, tm := range lookup.GetMods(game) {
+		states = append(states, &ModState{
+			Mod:     tm.Mod(),
+			Enabled: tm.Enabled(),
+			Choices: tm.Choices(),
+		})
+	}
+	return
+}
+
+// SetModStates puts the game's mods back in states. Mods that were removed since are tracked again, mods that are not
+// in states were added later and are disabled.
+func SetModStates(game config.GameDef, states []*ModState) error {
+	restored := make(map[mods.ModID]bool)
+	for _, s := range states {
+		tm, found := lookup.GetModByID(game, s.Mod.ID())
+		if !found {
+			tm = mods.NewTrackerMod(s.Mod, game)
+			lookup.SetMod(game, tm)
+		}
+		tm.SetMod(s.Mod)
+		tm.SetUpdatedMod(nil)
+		if err := saveMoogle(tm); err != nil {
+			return err
+		}
+		tm.SetChoices(s.Choices)
+		if s.Enabled {
+			tm.Enable()
+		} else {
+			tm.Disable()
+		}
+		restored[tm.ID()] = true
+	}
+	for _, tm := range lookup.GetMods(game) {
+		if !restored[tm.ID()] {
+			tm.Disable()
+		}
+	}
+	return save()
+}
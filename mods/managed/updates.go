@@ -9,10 +9,14 @@ import (
 	"github.com/kiamev/moogle-mod-manager/discover/repo"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/ui/util"
+	"github.com/kiamev/moogle-mod-manager/version"
 	"strings"
 	"sync"
 )
 
+// ErrUnknownUpdates is returned by CheckForUpdates when some versions could not be compared. Every other mod was checked.
+var ErrUnknownUpdates = errors.New("could not tell whether these mods have updates, please check them manually")
+
 func CheckForUpdates(game config.GameDef, result func(err error)) {
 	var (
 		dispatcher = workerpool.NewDispatcher(
@@ -49,23 +53,33 @@ func CheckForUpdates(game config.GameDef, result func(err error)) {
 		}
 	}
 	wg.Wait()
+	var incomparable []string
 	for _, uc := range ucs {
 		if uc.getError() != nil {
 			result(uc.getError())
 			return
 		}
+		if uc.getIncomparable() != nil {
+			incomparable = append(incomparable, uc.getIncomparable().Error())
+		}
+	}
+	if len(incomparable) > 0 {
+		result(fmt.Errorf("%w:\n%s", ErrUnknownUpdates, strings.Join(incomparable, "\n")))
+		return
 	}
 	result(nil)
 }
 
 type updateChecker interface {
 	getError() error
+	getIncomparable() error
 }
 
 type hostedUpdateChecker struct {
-	tm  mods.TrackedMod
-	wg  *sync.WaitGroup
-	err error
+	tm           mods.TrackedMod
+	wg           *sync.WaitGroup
+	err          error
+	incomparable error
 }
 
 func (c *hostedUpdateChecker) Process() error {
@@ -81,9 +95,7 @@ func (c *hostedUpdateChecker) Process() error {
 		util.ShowErrorLong(errors.New("Could not download remote version for " + c.tm.DisplayName()))
 		return nil
 	}
-	if isVersionNewer(c.tm.Mod().Version, remoteMod.Version) {
-		markForUpdate(c.tm, remoteMod)
-	}
+	c.incomparable = markIfNewer(c.tm, remoteMod)
 	return nil
 }
 
@@ -91,11 +103,16 @@ func (c *hostedUpdateChecker) getError() error {
 	return c.err
 }
 
+func (c *hostedUpdateChecker) getIncomparable() error {
+	return c.incomparable
+}
+
 type remoteUpdateChecker struct {
-	tm     mods.TrackedMod
-	wg     *sync.WaitGroup
-	client remote.Client
-	err    error
+	tm           mods.TrackedMod
+	wg           *sync.WaitGroup
+	client       remote.Client
+	err          error
+	incomparable error
 }
 
 func (c *remoteUpdateChecker) Process() error {
@@ -106,9 +123,7 @@ func (c *remoteUpdateChecker) Process() error {
 		return nil
 	}
 	if found && mod != nil {
-		if isVersionNewer(mod.Version, c.tm.Mod().Version) {
-			markForUpdate(c.tm, mod)
-		}
+		c.incomparable = markIfNewer(c.tm, mod)
 	}
 	return nil
 }
@@ -117,18 +132,20 @@ func (c *remoteUpdateChecker) getError() error {
 	return c.err
 }
 
-func isVersionNewer(new string, old string) bool {
-	if new == old {
-		return false
+func (c *remoteUpdateChecker) getIncomparable() error {
+	return c.incomparable
+}
+
+// markIfNewer flags tm for update when remote is newer. It returns an error when the versions cannot be ordered.
+func markIfNewer(tm mods.TrackedMod, remote *mods.Mod) error {
+	newer, err := version.IsNewer(remote.Version, tm.Mod().Version)
+	if err != nil {
+		return fmt.Errorf("%s: installed %s, available %s", tm.DisplayName(), tm.Mod().Version, remote.Version)
 	}
-	newSl := strings.Split(new, ".")
-	oldSl := strings.Split(old, ".")
-	for i := 0; i < len(newSl) && i < len(oldSl); i++ {
-		if newSl[i] > oldSl[i] {
-			return true
-		}
+	if newer {
+		markForUpdate(tm, remote)
 	}
-	return len(newSl) > len(oldSl)
+	return nil
 }
 
 func markForUpdate(tm mods.TrackedMod, mod *mods.Mod) {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kiamev/moogle-mod-manager/config"
 	"github.com/kiamev/moogle-mod-manager/util"
+	ver "github.com/kiamev/moogle-mod-manager/version"
 )
 
 type (
@@ -187,6 +188,11 @@ func (m *Mod) Validate() string {
 		if d.Name == "" {
 			sb.WriteString("Downloadables' name is required\n")
 		}
+		if d.Sha256 != "" && !sha256Pattern.MatchString(d.Sha256) {
+			sb.WriteString(fmt.Sprintf("Downloadables [%s]'s Sha256 must be 64 hexadecimal characters\n", d.Name))
+		} else if d.Size < 0 {
+			sb.WriteString(fmt.Sprintf("Downloadables [%s]'s Size cannot be negative\n", d.Name))
+		}
 		// TODO add more validations
 		dlableNames[d.Name] = true
 	}
@@ -211,9 +217,9 @@ func (m *Mod) Validate() string {
 				i := strings.LastIndex(u.Path, "/")
 				j := strings.LastIndex(u.Path, ".")
 				if i == -1 || j == -1 {
-					sb.WriteString(fmt.Sprintf("Downloadables [%s]'s Source [%s] is not a valid url, maybe missing .zip/.rar/.7z\n", d.Name, s))
+					sb.WriteString(fmt.Sprintf("Downloadables [%s]'s Source [%s] is not a valid url, maybe missing .zip/.rar/.7z/.tar.gz\n", d.Name, s))
 				}
-				s = u.Path[i+1 : j]
+				s = util.TrimArchiveExt(u.Path[i+1:])
 				if d.Name != s {
 					sb.WriteString(fmt.Sprintf("Downloadables [%s]'s Source [%s] must be the same as the name after the extension is removed\n", d.Name, s))
 				}
@@ -265,6 +271,24 @@ func (m *Mod) Validate() string {
 		if _, ok := dlableNames[ad.DownloadName]; !ok {
 			sb.WriteString("Always Download's downloadable doesn't exist\n")
 		}
+		if err := ad.ValidatePatterns(); err != nil {
+			sb.WriteString(fmt.Sprintf("AlwaysDownload [%s]'s %v\n", ad.DownloadName, err))
+		}
+	}
+
+	if m.ModCompatibility != nil {
+		for _, mc := range append(append([]*ModCompat(nil), m.ModCompatibility.Requires...), m.ModCompatibility.Forbids...) {
+			if _, err := mc.VersionRange(); err != nil {
+				sb.WriteString(fmt.Sprintf("Compatibility [%s]: %v\n", mc.ModID(), err))
+			}
+		}
+		for _, oc := range m.ModCompatibility.OrderConstraints {
+			if oc.ModID() == "" {
+				sb.WriteString("Order Constraint's Mod is required\n")
+			} else if oc.Order != Before && oc.Order != After {
+				sb.WriteString(fmt.Sprintf("Order Constraint [%s] must be Before or After\n", oc.ModID()))
+			}
+		}
 	}
 
 	roots := 0
@@ -297,6 +321,9 @@ func (m *Mod) Validate() string {
 				if _, ok := dlableNames[ch.DownloadFiles.DownloadName]; !ok {
 					sb.WriteString(fmt.Sprintf("Configuration's [%s] Choice [%s]'s downloadable doesn't exist\n", c.Name, ch.Name))
 				}
+				if err := ch.DownloadFiles.ValidatePatterns(); err != nil {
+					sb.WriteString(fmt.Sprintf("Configuration's [%s] Choice [%s]'s %v\n", c.Name, ch.Name, err))
+				}
 			} else if ch.DownloadFiles != nil && ch.DownloadFiles.DownloadName == "" && (len(ch.DownloadFiles.Files) > 0 || len(ch.DownloadFiles.Dirs) > 0) {
 				sb.WriteString(fmt.Sprintf("Configuration's [%s] Choice [%s]'s downloadable must be specified\n", c.Name, ch.Name))
 			}
@@ -323,12 +350,42 @@ func (m *Mod) Supports(game config.GameDef) error {
 	return fmt.Errorf("%s does not support %s", m.Name, game.Name())
 }
 
+// SupportsVersion reports whether the mod lists version as a supported version of game. A mod that lists no
+// versions of the game supports all of them.
+func (m *Mod) SupportsVersion(game config.GameDef, version config.VersionID) bool {
+	versions := m.GameVersions(game)
+	if len(versions) == 0 {
+		return true
+	}
+	for _, v := range versions {
+		if strings.EqualFold(string(v), string(version)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GameVersions returns the versions of game the mod lists as supported.
+func (m *Mod) GameVersions(game config.GameDef) (versions []config.VersionID) {
+	for _, g := range m.Games {
+		if g.ID == game.ID() {
+			for _, v := range g.Versions {
+				versions = append(versions, v.Version)
+			}
+		}
+	}
+	return
+}
+
 // NewModForVersion is used for specifying an updated mod with a version.
 func NewModForVersion(manual *Mod, remote *Mod) *Mod {
 	var m Mod
 	if manual != nil && manual.IsManuallyCreated {
 		m = *manual
-		m.Version = remote.Version
+		if !ver.Equal(manual.Version, remote.Version) {
+			// Keep the author's spelling when the remote only writes the same version differently
+			m.Version = remote.Version
+		}
 		m.Downloadables = remote.Downloadables
 	} else {
 		m = *remote
@@ -1,21 +1,38 @@
 package mods
 
 type (
-	//ModCompatOrder string
-	ModCompat struct {
+	ModCompatOrder string
+	ModCompat      struct {
 		Versions []string `json:"Version,omitempty" xml:"Versions,omitempty"`
 		ID       ModID    `json:"ModID,omitempty" xml:"ModID,omitempty"`
+		// Order is only used by OrderConstraints
+		Order ModCompatOrder `json:"Order,omitempty" xml:"Order,omitempty"`
 		//displayName string           `json:"-" xml:"-"`
 	}
 )
 
-//const (
-//	None   ModCompatOrder = ""
-//	Before ModCompatOrder = "Before"
-//	After  ModCompatOrder = "After"
-//)
-//var ModCompatOrders = []string{string(None), string(Before), string(After)}
+const (
+	None   ModCompatOrder = ""
+	Before ModCompatOrder = "Before"
+	After  ModCompatOrder = "After"
+)
+
+var ModCompatOrders = []string{string(None), string(Before), string(After)}
 
 func (c *ModCompat) ModID() ModID {
 	return c.ID
 }
+
+// Allows reports whether version is in the range of Versions. Having no Versions allows every version.
+func (c *ModCompat) Allows(version string) (bool, error) {
+	r, err := c.VersionRange()
+	if err != nil {
+		return false, err
+	}
+	return r.Contains(version), nil
+}
+
+// VersionRange parses Versions.
+func (c *ModCompat) VersionRange() (*VersionRange, error) {
+	return ParseVersionRange(c.Versions)
+}
@@ -0,0 +1,150 @@
+package mods
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// regexPrefix marks a ModFile or ModDir From that is a regular expression rather than a path or glob.
+	regexPrefix = "re:"
+	// globPrefix marks a From that is a glob whose [ ] are character classes, they are kept as path characters otherwise
+	globPrefix = "glob:"
+)
+
+var numberedCapture = regexp.MustCompile(`\$(\d+)`)
+
+// SourcePattern matches the paths in a download that a ModFile's or ModDir's From names with a glob, such as
+// "Data_v*/StreamingAssets/**" or "glob:Data_v[0-9]/x.bundle", or a regular expression starting with "re:". Every wildcard of a glob and every
+// group of a regular expression is captured and can be used in To as $1, $2 and so on. Matching ignores case.
+type SourcePattern struct {
+	from string
+	re   *regexp.Regexp
+}
+
+// IsPattern reports whether from is a glob or a regular expression instead of a path. A From without a prefix is only
+// a glob when it has a * or ?, which no file name can hold, so paths such as "FF6 [Steam]/x.bundle" stay exact.
+func IsPattern(from string) bool {
+	return strings.HasPrefix(from, regexPrefix) || strings.HasPrefix(from, globPrefix) || strings.ContainsAny(from, "*?")
+}
+
+// CompilePattern compiles a From that IsPattern.
+func CompilePattern(from string) (*SourcePattern, error) {
+	var expr string
+	if strings.HasPrefix(from, regexPrefix) {
+		expr = strings.TrimPrefix(from, regexPrefix)
+		if !strings.HasPrefix(expr, "^") {
+			expr = "^" + expr
+		}
+		if !strings.HasSuffix(expr, "$") {
+			expr += "$"
+		}
+	} else {
+		var err error
+		classes := strings.HasPrefix(from, globPrefix)
+		if expr, err = globToRegex(strings.TrimPrefix(from, globPrefix), classes); err != nil {
+			return nil, fmt.Errorf("pattern [%s] %v", from, err)
+		}
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("pattern [%s] is not valid: %v", from, err)
+	}
+	return &SourcePattern{from: from, re: re}, nil
+}
+
+func (p *SourcePattern) String() string {
+	return p.from
+}
+
+// Match reports whether p matches the whole of the slash separated path.
+func (p *SourcePattern) Match(path string) bool {
+	return p.re.MatchString(path)
+}
+
+// MatchDir returns the directory holding the file at path that p matches. When recursive is set, that is the
+// shortest of the file's directories p matches, otherwise only the file's own directory is tried.
+func (p *SourcePattern) MatchDir(file string, recursive bool) (dir string, ok bool) {
+	dir = path.Dir(file)
+	if dir == "." {
+		return "", false
+	}
+	if !recursive {
+		return dir, p.Match(dir)
+	}
+	sp := strings.Split(dir, "/")
+	for i := 1; i <= len(sp); i++ {
+		if d := strings.Join(sp[:i], "/"); p.Match(d) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// Expand replaces the $1, $2 or ${name} captures in to with what p captured from path.
+func (p *SourcePattern) Expand(to string, path string) string {
+	m := p.re.FindStringSubmatchIndex(path)
+	if m == nil || !strings.Contains(to, "$") {
+		return to
+	}
+	// $1_Data would otherwise name a group called "1_Data"
+	to = numberedCapture.ReplaceAllString(to, "$${$1}")
+	return string(p.re.ExpandString(nil, to, path, m))
+}
+
+// globToRegex turns a glob into a regular expression that captures each wildcard. "**" matches any number of
+// directories, including none when it is a whole path segment. [ ] are character classes only when classes is set.
+func globToRegex(glob string, classes bool) (string, error) {
+	var (
+		sb strings.Builder
+		g  = strings.Trim(strings.ReplaceAll(glob, "\\", "/"), "/")
+	)
+	sb.WriteString("^")
+	for i := 0; i < len(g); i++ {
+		switch c := g[i]; c {
+		case '*':
+			if i+1 < len(g) && g[i+1] == '*' {
+				i++
+				switch {
+				case i+1 < len(g) && g[i+1] == '/':
+					// "**/" matches no directory or several
+					i++
+					sb.WriteString("((?:[^/]*/)*)")
+				case i+1 == len(g) && strings.HasSuffix(sb.String(), "/"):
+					// "/**" at the end also matches the directory itself
+					s := strings.TrimSuffix(sb.String(), "/")
+					sb.Reset()
+					sb.WriteString(s)
+					sb.WriteString("(?:/(.*))?")
+				default:
+					sb.WriteString("(.*)")
+				}
+			} else {
+				sb.WriteString("([^/]*)")
+			}
+		case '?':
+			sb.WriteString("([^/])")
+		case '[':
+			if !classes {
+				sb.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			j := strings.IndexByte(g[i:], ']')
+			if j < 2 {
+				return "", fmt.Errorf("has an unclosed [")
+			}
+			class := g[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("([" + class + "])")
+			i += j
+		default:
+			sb.WriteString(regexp.QuoteMeta(g[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String(), nil
+}
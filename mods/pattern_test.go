@@ -0,0 +1,100 @@
+package mods
+
+import "testing"
+
+func TestIsPattern(t *testing.T) {
+	tests := []struct {
+		from string
+		want bool
+	}{
+		{"Data/x.bundle", false},
+		{"FF6 [Steam]/x.bundle", false},
+		{"Data_v*/x.bundle", true},
+		{"Data_v?/x.bundle", true},
+		{"glob:Data_v[0-9]/x.bundle", true},
+		{"re:Data_v\\d+/x.bundle", true},
+	}
+	for _, tt := range tests {
+		if got := IsPattern(tt.from); got != tt.want {
+			t.Errorf("IsPattern(%q) = %v, want %v", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestSourcePatternMatch(t *testing.T) {
+	tests := []struct {
+		from     string
+		path     string
+		want     bool
+		to       string
+		expanded string
+	}{
+		{"Data_v*/StreamingAssets/**", "Data_v2/StreamingAssets/a/b.png", true, "$1/$2", "2/a/b.png"},
+		{"Data_v*/StreamingAssets/**", "Data_v2/StreamingAssets", true, "", ""},
+		{"Data_v*/StreamingAssets/**", "Data_v2/Other/b.png", false, "", ""},
+		{"**/x.bundle", "x.bundle", true, "", ""},
+		{"**/x.bundle", "a/b/x.bundle", true, "$1", "a/b/"},
+		{"*.bundle", "a/x.bundle", false, "", ""},
+		{"?.txt", "A.TXT", true, "$1_Data", "A_Data"},
+		{"FF6 [Steam]/*.bundle", "FF6 [Steam]/x.bundle", true, "", ""},
+		{"FF6 [Steam]/*.bundle", "FF6 S/x.bundle", false, "", ""},
+		{"glob:Data_v[0-9]/x.bundle", "Data_v3/x.bundle", true, "v$1", "v3"},
+		{"glob:Data_v[!0-9]/x.bundle", "Data_v3/x.bundle", false, "", ""},
+		{"glob:Data_v[!0-9]/x.bundle", "Data_vb/x.bundle", true, "", ""},
+		{"re:Data_v(\\d+)/x\\.bundle", "Data_v12/x.bundle", true, "$1", "12"},
+		{"re:Data_v(\\d+)/x\\.bundle", "Data_v12/x.bundle.bak", false, "", ""},
+		{"re:data_(?P<v>\\w+)", "Data_abc", true, "${v}", "abc"},
+	}
+	for _, tt := range tests {
+		p, err := CompilePattern(tt.from)
+		if err != nil {
+			t.Fatalf("CompilePattern(%q) error = %v", tt.from, err)
+		}
+		if got := p.Match(tt.path); got != tt.want {
+			t.Errorf("%q.Match(%q) = %v, want %v", tt.from, tt.path, got, tt.want)
+			continue
+		}
+		if tt.to != "" {
+			if got := p.Expand(tt.to, tt.path); got != tt.expanded {
+				t.Errorf("%q.Expand(%q, %q) = %q, want %q", tt.from, tt.to, tt.path, got, tt.expanded)
+			}
+		}
+	}
+}
+
+func TestCompilePatternErrors(t *testing.T) {
+	for _, from := range []string{"glob:Data_v[0-9/x.bundle", "glob:Data[]", "re:Data_v(\\d+"} {
+		if _, err := CompilePattern(from); err == nil {
+			t.Errorf("CompilePattern(%q) error = nil", from)
+		}
+	}
+	// Without the glob: prefix [ is an ordinary character
+	if _, err := CompilePattern("Data_v[0-9/*.bundle"); err != nil {
+		t.Errorf("CompilePattern() error = %v", err)
+	}
+}
+
+func TestSourcePatternMatchDir(t *testing.T) {
+	tests := []struct {
+		from      string
+		file      string
+		recursive bool
+		want      string
+		ok        bool
+	}{
+		{"Data_v*", "Data_v2/a/b.png", true, "Data_v2", true},
+		{"Data_v*", "Data_v2/a/b.png", false, "", false},
+		{"Data_v*/a", "Data_v2/a/b.png", false, "Data_v2/a", true},
+		{"Data_v*", "b.png", true, "", false},
+	}
+	for _, tt := range tests {
+		p, err := CompilePattern(tt.from)
+		if err != nil {
+			t.Fatalf("CompilePattern(%q) error = %v", tt.from, err)
+		}
+		got, ok := p.MatchDir(tt.file, tt.recursive)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("%q.MatchDir(%q, %v) = %q, %v, want %q, %v", tt.from, tt.file, tt.recursive, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -39,6 +39,9 @@ func NewToInstallForMod(mod *Mod, downloadFiles []*DownloadFiles) (result []*ToI
 		}
 		i.Files = append(i.Files, df.Files...)
 		i.Dirs = append(i.Dirs, df.Dirs...)
+		i.Include = append(i.Include, df.Include...)
+		i.Exclude = append(i.Exclude, df.Exclude...)
+		i.ReplaceDefaultExcludes = i.ReplaceDefaultExcludes || df.ReplaceDefaultExcludes
 	}
 	for n, df := range dfLookup {
 		dl := mLookup[n]
@@ -3,6 +3,7 @@ package mods
 import (
 	"github.com/kiamev/moogle-mod-manager/config"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -27,11 +28,14 @@ type (
 		SetUpdatedMod(m *Mod)
 		MoogleModFile() string
 		InstallType(game config.GameDef) config.InstallType
+		Choices() SelectedChoices
+		SetChoices(c SelectedChoices)
 	}
 	// TrackedModConc is public for serialization purposes
 	TrackedModConc struct {
-		IsEnabled      bool   `json:"Enabled"`
-		MoogleModFile_ string `json:"MoogleModFile"`
+		IsEnabled      bool            `json:"Enabled"`
+		MoogleModFile_ string          `json:"MoogleModFile"`
+		Choices_       SelectedChoices `json:"Choices,omitempty"`
 		//Installed     []*InstalledDownload `json:"Installed"`
 		Mod_         *Mod   `json:"-"`
 		UpdatedMod_  *Mod   `json:"-"`
@@ -43,6 +47,14 @@ func (m *TrackedModConc) InstallType(game config.GameDef) config.InstallType {
 	return m.Mod().InstallType(game)
 }
 
+func (m *TrackedModConc) Choices() SelectedChoices {
+	return m.Choices_
+}
+
+func (m *TrackedModConc) SetChoices(c SelectedChoices) {
+	m.Choices_ = c
+}
+
 func (m *TrackedModConc) DisplayNamePtr() *string {
 	return &m.DisplayName_
 }
@@ -86,8 +98,9 @@ func (m *TrackedModConc) Disable() {
 
 func NewTrackerMod(mod *Mod, game config.GameDef) TrackedMod {
 	tm := &TrackedModConc{
-		IsEnabled: false,
-		Mod_:      mod,
+		IsEnabled:    false,
+		Mod_:         mod,
+		DisplayName_: string(mod.Name),
 	}
 	tm.MoogleModFile_ = filepath.Join(config.Get().GetModsFullPath(game), tm.ID().AsDir(), moogleModName)
 	return tm
@@ -111,6 +124,9 @@ func (m *TrackedModConc) Mod() *Mod {
 
 func (m *TrackedModConc) SetMod(mod *Mod) {
 	m.Mod_ = mod
+	if m.DisplayName_ == "" && mod != nil {
+		m.DisplayName_ = string(mod.Name)
+	}
 }
 
 func (m *TrackedModConc) Enabled() bool {
@@ -137,3 +153,46 @@ func NewInstalledDownload(name, version string) *InstalledDownload {
 		Version: version,
 	}
 }
+
+// SortTracked orders tracked mods by name and then id.
+func SortTracked(tms []TrackedMod) []TrackedMod {
+	sort.Slice(tms, func(i, j int) bool {
+		a, b := tms[i].Mod().Name, tms[j].Mod().Name
+		if a != b {
+			return a < b
+		}
+		return tms[i].ID() < tms[j].ID()
+	})
+	return tms
+}
+
+// SortByDependencies orders tms so every mod comes after the mods it requires. Mods are otherwise sorted by name.
+func SortByDependencies(tms []TrackedMod) []TrackedMod {
+	var (
+		byID    = make(map[ModID]TrackedMod)
+		visited = make(map[ModID]bool)
+		result  = make([]TrackedMod, 0, len(tms))
+		visit   func(tm TrackedMod)
+	)
+	for _, tm := range tms {
+		byID[tm.ID()] = tm
+	}
+	visit = func(tm TrackedMod) {
+		if visited[tm.ID()] {
+			return
+		}
+		visited[tm.ID()] = true
+		if c := tm.Mod().ModCompatibility; c != nil {
+			for _, r := range c.Requires {
+				if req, found := byID[r.ModID()]; found {
+					visit(req)
+				}
+			}
+		}
+		result = append(result, tm)
+	}
+	for _, tm := range SortTracked(tms) {
+		visit(tm)
+	}
+	return result
+}
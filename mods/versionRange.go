@@ -0,0 +1,125 @@
+package mods
+
+import (
+	"fmt"
+	"strings"
+
+	ver "github.com/kiamev/moogle-mod-manager/version"
+)
+
+type (
+	versionOp         string
+	versionComparison struct {
+		op      versionOp
+		version string
+	}
+	// VersionRange is the set of versions a ModCompat's Versions allow.
+	// Exact versions are alternatives, comparisons such as ">=1.2.0" or "<2.0" must all hold.
+	VersionRange struct {
+		exact       []string
+		comparisons []versionComparison
+	}
+)
+
+const (
+	opEq  versionOp = "="
+	opNe  versionOp = "!="
+	opGt  versionOp = ">"
+	opGte versionOp = ">="
+	opLt  versionOp = "<"
+	opLte versionOp = "<="
+)
+
+// Longest operators first so ">=" is not read as ">"
+var versionOps = []versionOp{opGte, opLte, opNe, opEq + "=", opGt, opLt, opEq}
+
+// ParseVersionRange parses entries such as "1.2.3", ">=1.2.0", "< 2.0" or ">= 1.2, <2.0".
+func ParseVersionRange(versions []string) (*VersionRange, error) {
+	r := &VersionRange{}
+	for _, v := range versions {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			var op versionOp
+			for _, o := range versionOps {
+				if strings.HasPrefix(part, string(o)) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				r.exact = append(r.exact, part)
+				continue
+			}
+			version := strings.TrimSpace(part[len(op):])
+			if version == "" {
+				return nil, fmt.Errorf("version constraint [%s] is missing a version", v)
+			}
+			if op == opEq+"=" {
+				op = opEq
+			}
+			r.comparisons = append(r.comparisons, versionComparison{op: op, version: version})
+		}
+	}
+	return r, nil
+}
+
+func (r *VersionRange) IsEmpty() bool {
+	return len(r.exact) == 0 && len(r.comparisons) == 0
+}
+
+// Contains reports whether version is allowed. An empty range allows every version.
+func (r *VersionRange) Contains(version string) bool {
+	if r.IsEmpty() {
+		return true
+	}
+	for _, e := range r.exact {
+		if ver.Equal(version, e) {
+			return true
+		}
+	}
+	if len(r.comparisons) == 0 {
+		return false
+	}
+	for _, c := range r.comparisons {
+		if !c.matches(version) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *VersionRange) String() string {
+	var sl []string
+	if len(r.exact) > 0 {
+		sl = append(sl, strings.Join(r.exact, " or "))
+	}
+	for _, c := range r.comparisons {
+		sl = append(sl, string(c.op)+c.version)
+	}
+	return strings.Join(sl, ", ")
+}
+
+func (c versionComparison) matches(version string) bool {
+	i, err := ver.Compare(version, c.version)
+	if err != nil {
+		// Versions that cannot be ordered only satisfy "!="
+		return c.op == opNe
+	}
+	switch c.op {
+	case opEq:
+		return i == 0
+	case opNe:
+		return i != 0
+	case opGt:
+		return i > 0
+	case opGte:
+		return i >= 0
+	case opLt:
+		return i < 0
+	case opLte:
+		return i <= 0
+	}
+	return false
+}
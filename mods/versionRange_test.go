@@ -0,0 +1,74 @@
+package mods
+
+import "testing"
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		in      []string
+		want    string
+		wantErr bool
+	}{
+		{nil, "", false},
+		{[]string{"1.2.3"}, "1.2.3", false},
+		{[]string{"1.0", "1.1"}, "1.0 or 1.1", false},
+		{[]string{">=1.2.0"}, ">=1.2.0", false},
+		{[]string{"< 2.0"}, "<2.0", false},
+		{[]string{">= 1.2, <2.0"}, ">=1.2, <2.0", false},
+		{[]string{">=1.2,<2.0"}, ">=1.2, <2.0", false},
+		{[]string{"==1.2"}, "=1.2", false},
+		{[]string{"!= 1.5"}, "!=1.5", false},
+		{[]string{"<=3", ">1"}, "<=3, >1", false},
+		{[]string{" , 1.0 ,"}, "1.0", false},
+		{[]string{">="}, "", true},
+		{[]string{"1.0, <"}, "", true},
+	}
+	for _, tt := range tests {
+		r, err := ParseVersionRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersionRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && r.String() != tt.want {
+			t.Errorf("ParseVersionRange(%q) = %q, want %q", tt.in, r.String(), tt.want)
+		}
+	}
+}
+
+func TestVersionRangeContains(t *testing.T) {
+	tests := []struct {
+		versions []string
+		version  string
+		want     bool
+	}{
+		{nil, "1.0", true},
+		{[]string{"1.2.3"}, "1.2.3", true},
+		{[]string{"1.2.3"}, "v1.2.3", true},
+		{[]string{"1.2.3"}, "1.2.4", false},
+		{[]string{"1.0", "1.1"}, "1.1", true},
+		{[]string{">=1.2.0"}, "1.2", true},
+		{[]string{">=1.2.0"}, "1.1.9", false},
+		{[]string{">=1.2,<2.0"}, "1.9", true},
+		{[]string{">=1.2,<2.0"}, "2.0", false},
+		{[]string{">= 1.2, <2.0"}, "1.9", true},
+		{[]string{">= 1.2, <2.0"}, "2.0", false},
+		{[]string{">1.0", "<= 1.5"}, "1.5", true},
+		{[]string{">1.0", "<= 1.5"}, "1.0", false},
+		{[]string{"!=1.5"}, "1.4", true},
+		{[]string{"!=1.5"}, "1.5", false},
+		{[]string{"=2.0"}, "2.0.0", true},
+		{[]string{"1.0", ">=2.0"}, "1.0", true},
+		{[]string{"1.0", ">=2.0"}, "2.1", true},
+		{[]string{"1.0", ">=2.0"}, "1.5", false},
+		{[]string{">=1.0"}, "Final", false},
+		{[]string{"!=1.0"}, "Final", true},
+	}
+	for _, tt := range tests {
+		r, err := ParseVersionRange(tt.versions)
+		if err != nil {
+			t.Fatalf("ParseVersionRange(%q) error = %v", tt.versions, err)
+		}
+		if got := r.Contains(tt.version); got != tt.want {
+			t.Errorf("%q.Contains(%q) = %v, want %v", tt.versions, tt.version, got, tt.want)
+		}
+	}
+}
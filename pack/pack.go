@@ -0,0 +1,256 @@
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// Result is what Pack wrote and how the mod's download was filled in.
+type Result struct {
+	Archive string
+	Sha256  string
+	Size    int64
+	// Files are the packed files, relative to the packed folder
+	Files []string
+	// Included are the packed files the default excludes would have left out, added to the mappings' Include
+	Included []string
+	Download *mods.Download
+	Mappings *mods.DownloadFiles
+}
+
+// Pack zips dir, a folder laid out like the game's directory, into archiveFile and fills in the mod's download of
+// that archive: its checksum and size, and the Dirs and Files that install everything where it sits in dir. A folder
+// holding what goes in the game's BaseDir, rather than the BaseDir itself, is installed into the BaseDir. For games
+// that install into archives, each folder named like one of the game's archive files is injected into that archive.
+func Pack(game config.GameDef, mod *mods.Mod, dir string, archiveFile string) (r *Result, err error) {
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+	if archiveFile, err = filepath.Abs(archiveFile); err != nil {
+		return
+	}
+	if rel, e := filepath.Rel(dir, archiveFile); e == nil && !strings.HasPrefix(rel, "..") {
+		return nil, errors.New("the archive cannot be written inside the folder being packed")
+	}
+
+	r = &Result{Archive: archiveFile}
+	if r.Files, r.Included, err = collect(dir); err != nil {
+		return nil, err
+	}
+	if len(r.Files) == 0 {
+		return nil, fmt.Errorf("%s has no files to pack", dir)
+	}
+
+	df := &mods.DownloadFiles{DownloadName: util.TrimArchiveExt(filepath.Base(archiveFile))}
+	for _, f := range r.Included {
+		df.Include = append(df.Include, escapeGlob(f))
+	}
+	if mod.InstallType(game) == config.MoveToArchive {
+		err = mapToArchives(game, df, r.Files, baseDirPrefix(game, dir))
+	} else {
+		mapToGameDir(df, r.Files, baseDirPrefix(game, dir))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, len(r.Files))
+	for _, f := range r.Files {
+		files[f] = filepath.Join(dir, filepath.FromSlash(f))
+	}
+	if err = os.MkdirAll(filepath.Dir(archiveFile), 0777); err != nil {
+		return nil, err
+	}
+	if err = archive.ZipCreate(archiveFile, files); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", archiveFile, err)
+	}
+	if r.Sha256, err = util.HashFile(archiveFile); err != nil {
+		return nil, err
+	}
+	var fi os.FileInfo
+	if fi, err = os.Stat(archiveFile); err != nil {
+		return nil, err
+	}
+	r.Size = fi.Size()
+
+	r.Download, r.Mappings = fill(game, mod, df, r)
+	return
+}
+
+// Target describes where a file or dir mapping installs to.
+func Target(to string, toArchive *string) string {
+	if toArchive == nil {
+		return to
+	}
+	if to == "" {
+		return "archive " + *toArchive
+	}
+	return fmt.Sprintf("%s in archive %s", to, *toArchive)
+}
+
+// collect returns every file in dir and, of those, the ones the default excludes would leave out when installing.
+func collect(dir string) (files []string, excluded []string, err error) {
+	skip := &mods.DownloadFiles{}
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." || d.IsDir() {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		files = append(files, rel)
+		if skip.Skip(rel) {
+			excluded = append(excluded, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	sort.Strings(excluded)
+	return
+}
+
+// escapeGlob makes the file path f a glob that matches f. Globs have no escapes, so the characters that are not
+// literal in them match any single character instead.
+func escapeGlob(f string) string {
+	return strings.Map(func(c rune) rune {
+		if strings.ContainsRune("*?[", c) {
+			return '?'
+		}
+		return c
+	}, f)
+}
+
+// baseDirPrefix returns the game's BaseDir when dir holds its contents instead of the BaseDir itself.
+func baseDirPrefix(game config.GameDef, dir string) string {
+	base := strings.Trim(filepath.ToSlash(string(game.BaseDir())), "/")
+	if base == "" {
+		return ""
+	}
+	if fi, err := os.Stat(filepath.Join(dir, filepath.FromSlash(base))); err == nil && fi.IsDir() {
+		return ""
+	}
+	return base
+}
+
+// mapToGameDir installs each of the packed folder's top level files and directories to the same place in the game's
+// directory.
+func mapToGameDir(df *mods.DownloadFiles, files []string, prefix string) {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		top, rest, isDir := strings.Cut(f, "/")
+		if seen[top] {
+			continue
+		}
+		seen[top] = true
+		if isDir && rest != "" {
+			df.Dirs = append(df.Dirs, &mods.ModDir{From: top, To: path.Join(prefix, top), Recursive: true})
+		} else {
+			df.Files = append(df.Files, &mods.ModFile{From: top, To: path.Join(prefix, top)})
+		}
+	}
+}
+
+// mapToArchives injects each folder that is named like one of the game's archive files into that archive. Every
+// packed file must be within one of them.
+func mapToArchives(game config.GameDef, df *mods.DownloadFiles, files []string, prefix string) error {
+	gameDir, err := config.Get().GetDir(game, config.GameDirKind)
+	if err != nil || gameDir == "" {
+		return fmt.Errorf("the directory of %s is needed to find the archives the mod's files go in", game.Name())
+	}
+	archives := make(map[string]bool)
+	for _, f := range files {
+		from, toArchive, found := findArchive(gameDir, f, prefix)
+		if !found {
+			return fmt.Errorf("%s is not within a folder named like one of the archives of %s", f, game.Name())
+		}
+		if !archives[from] {
+			archives[from] = true
+			df.Dirs = append(df.Dirs, &mods.ModDir{From: from, To: "", Recursive: true, ToArchive: &toArchive})
+		}
+	}
+	return nil
+}
+
+// findArchive returns the folder of f that is named like an archive file in the game's directory, and the archive's
+// path relative to it.
+func findArchive(gameDir string, f string, prefix string) (from string, toArchive string, found bool) {
+	dirs := strings.Split(path.Dir(f), "/")
+	for i := range dirs {
+		from = strings.Join(dirs[:i+1], "/")
+		toArchive = path.Join(prefix, from)
+		if fi, err := os.Stat(filepath.Join(gameDir, filepath.FromSlash(toArchive))); err == nil && !fi.IsDir() {
+			return from, toArchive, true
+		}
+	}
+	return "", "", false
+}
+
+// fill adds, or updates, the mod's download of the packed archive and its mappings and makes sure the mod lists the
+// game.
+func fill(game config.GameDef, mod *mods.Mod, df *mods.DownloadFiles, r *Result) (*mods.Download, *mods.DownloadFiles) {
+	var d *mods.Download
+	for _, dl := range mod.Downloadables {
+		if dl.Name == df.DownloadName {
+			d = dl
+			break
+		}
+	}
+	if d == nil {
+		d = &mods.Download{Name: df.DownloadName}
+		mod.Downloadables = append(mod.Downloadables, d)
+	}
+	d.Version = mod.Version
+	d.Sha256 = r.Sha256
+	d.Size = r.Size
+	if d.Hosted == nil && mod.ModKind.Kinds.Is(mods.HostedAt) {
+		// Left for the author to add where the archive is uploaded to
+		d.Hosted = &mods.HostedDownloadable{}
+	}
+
+	var existing *mods.DownloadFiles
+	for _, a := range mod.AlwaysDownload {
+		if a.DownloadName == df.DownloadName {
+			existing = a
+			break
+		}
+	}
+	if existing == nil {
+		mod.AlwaysDownload = append(mod.AlwaysDownload, df)
+		existing = df
+	} else {
+		existing.Files = df.Files
+		existing.Dirs = df.Dirs
+		for _, i := range df.Include {
+			if !contains(existing.Include, i) {
+				existing.Include = append(existing.Include, i)
+			}
+		}
+	}
+
+	if mod.Supports(game) != nil {
+		mod.Games = append(mod.Games, &mods.Game{ID: game.ID()})
+	}
+	return d, existing
+}
+
+func contains(sl []string, s string) bool {
+	for _, v := range sl {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+func TestEscapeGlob(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"readme.txt", "readme.txt"},
+		{"docs/read me.md", "docs/read me.md"},
+		{"FF6 [Steam]/x.bundle", "FF6 ?Steam]/x.bundle"},
+		{"what?.txt", "what?.txt"},
+		{"a*b.txt", "a?b.txt"},
+	}
+	for _, tt := range tests {
+		if got := escapeGlob(tt.in); got != tt.want {
+			t.Errorf("escapeGlob(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMapToGameDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []string
+		prefix string
+		dirs   []*mods.ModDir
+		fs     []*mods.ModFile
+	}{
+		{"top level", []string{"a.txt", "b.txt"}, "", nil, []*mods.ModFile{
+			{From: "a.txt", To: "a.txt"},
+			{From: "b.txt", To: "b.txt"},
+		}},
+		{"dirs once", []string{"Data/a.txt", "Data/sub/b.txt", "x.dll"}, "", []*mods.ModDir{
+			{From: "Data", To: "Data", Recursive: true},
+		}, []*mods.ModFile{
+			{From: "x.dll", To: "x.dll"},
+		}},
+		{"base dir", []string{"StreamingAssets/a.bundle", "x.dll"}, "FF6_Data", []*mods.ModDir{
+			{From: "StreamingAssets", To: "FF6_Data/StreamingAssets", Recursive: true},
+		}, []*mods.ModFile{
+			{From: "x.dll", To: "FF6_Data/x.dll"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := &mods.DownloadFiles{}
+			mapToGameDir(df, tt.files, tt.prefix)
+			if !reflect.DeepEqual(df.Dirs, tt.dirs) {
+				t.Errorf("Dirs = %v, want %v", dirs(df.Dirs), dirs(tt.dirs))
+			}
+			if !reflect.DeepEqual(df.Files, tt.fs) {
+				t.Errorf("Files = %v, want %v", modFiles(df.Files), modFiles(tt.fs))
+			}
+		})
+	}
+}
+
+func TestMapToArchives(t *testing.T) {
+	var (
+		game    = configtest.Game(t)
+		gameDir = filepath.Join(config.PWD, "game")
+	)
+	for _, a := range []string{"data.zip", "Base/assets.pak"} {
+		f := filepath.Join(gameDir, filepath.FromSlash(a))
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config.Get().GameDirs[string(game.ID())] = &config.GameDir{Dir: gameDir}
+
+	tests := []struct {
+		name    string
+		files   []string
+		prefix  string
+		want    []string
+		wantErr bool
+	}{
+		{"archive", []string{"data.zip/a.txt", "data.zip/sub/b.txt"}, "", []string{"data.zip -> data.zip"}, false},
+		{"nested archive", []string{"Base/assets.pak/a.txt", "data.zip/b.txt"}, "", []string{
+			"Base/assets.pak -> Base/assets.pak",
+			"data.zip -> data.zip",
+		}, false},
+		{"base dir", []string{"assets.pak/a.txt"}, "Base", []string{"assets.pak -> Base/assets.pak"}, false},
+		{"outside archives", []string{"data.zip/a.txt", "other/b.txt"}, "", nil, true},
+		{"top level file", []string{"a.txt"}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := &mods.DownloadFiles{}
+			err := mapToArchives(game, df, tt.files, tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mapToArchives() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, d := range df.Dirs {
+				if d.To != "" || !d.Recursive || d.ToArchive == nil {
+					t.Errorf("dir %s = %+v", d.From, d)
+					continue
+				}
+				got = append(got, d.From+" -> "+*d.ToArchive)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dirs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func dirs(ds []*mods.ModDir) (s []mods.ModDir) {
+	for _, d := range ds {
+		s = append(s, *d)
+	}
+	return
+}
+
+func modFiles(fs []*mods.ModFile) (s []mods.ModFile) {
+	for _, f := range fs {
+		s = append(s, *f)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+type (
+	AutoOptions struct {
+		// InstallRequired installs missing required mods instead of cancelling.
+		InstallRequired bool
+		// ReplaceConflicts lets the mod being installed win every conflict. Otherwise the current owner keeps its files.
+		ReplaceConflicts bool
+		// RememberConflicts adds the outcome of the conflicts to the game's load order.
+		RememberConflicts bool
+		// AnyGameVersion enables mods that do not list the installed game version instead of cancelling.
+		AnyGameVersion bool
+		// Choices maps a configuration's name to the names of the choices to select.
+		// Configurations without an entry use their first choice, the same default the config installer shows.
+		Choices map[string][]string
+	}
+	auto struct {
+		AutoOptions
+	}
+)
+
+// NewAuto creates a Prompter that never waits on a user and answers from opts instead.
+func NewAuto(opts AutoOptions) Prompter {
+	return &auto{AutoOptions: opts}
+}
+
+func (p *auto) Configure(_ config.GameDef, mod *mods.Mod, _ string) (mods.Result, []*mods.Choice, error) {
+	choices, err := walkConfigurations(mod, func(c *mods.Configuration) ([]*mods.Choice, error) {
+		names, found := p.Choices[c.Name]
+		if !found {
+			return c.Choices[:1], nil
+		}
+		return selectByName(c, names)
+	})
+	if err != nil {
+		return mods.Error, nil, err
+	}
+	return mods.Ok, choices, nil
+}
+
+func (p *auto) ConfirmDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (mods.Result, error) {
+	if !isManualDownload(tm) {
+		return mods.Ok, nil
+	}
+	missing, err := missingManualDownloads(game, tm, toInstall)
+	if err != nil {
+		return mods.Error, err
+	}
+	if len(missing) > 0 {
+		sb := strings.Builder{}
+		sb.WriteString(fmt.Sprintf("[%s] requires files that must be downloaded manually:\n", tm.DisplayName()))
+		for _, m := range missing {
+			sb.WriteString(fmt.Sprintf("  %s\n    place in: %s\n", m.uri, m.dir))
+		}
+		return mods.Error, errors.New(sb.String())
+	}
+	return mods.Ok, nil
+}
+
+func (p *auto) ResolveConflicts(mod *mods.Mod, conflicts []*files.Conflict) (mods.Result, bool) {
+	for _, c := range conflicts {
+		if p.ReplaceConflicts {
+			c.Selection = mod
+		} else {
+			c.Selection = c.Owner
+		}
+	}
+	return mods.Ok, p.RememberConflicts
+}
+
+func (p *auto) EnableRequiredMod(_ mods.ModName, _ *mods.Mod) mods.Result {
+	if p.InstallRequired {
+		return mods.Ok
+	}
+	return mods.Cancel
+}
+
+func (p *auto) UpdateRequiredMod(_ mods.ModName, _ mods.TrackedMod, _ *mods.Mod) mods.Result {
+	if p.InstallRequired {
+		return mods.Ok
+	}
+	return mods.Cancel
+}
+
+func (p *auto) Missing7zip(url string) (mods.Result, error) {
+	return mods.Error, missing7zip(url)
+}
+
+func (p *auto) AllowGameVersion(_ *mods.Mod, _ config.VersionID) mods.Result {
+	if p.AnyGameVersion {
+		return mods.Ok
+	}
+	return mods.Cancel
+}
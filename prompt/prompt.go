@@ -0,0 +1,40 @@
+package prompt
+
+import (
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+type (
+	// Prompter answers every question an action needs answered while installing or uninstalling a mod.
+	// All calls block until an answer is available.
+	Prompter interface {
+		// Configure walks the mod's configurations and returns the selected choices.
+		Configure(game config.GameDef, mod *mods.Mod, modPath string) (mods.Result, []*mods.Choice, error)
+		// ConfirmDownloads makes sure the files needed by toInstall may be downloaded or have been manually downloaded.
+		ConfirmDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (mods.Result, error)
+		// ResolveConflicts sets the Selection of every conflict. remember is true when the choices should be added to
+		// the game's load order so they are not asked again.
+		ResolveConflicts(mod *mods.Mod, conflicts []*files.Conflict) (result mods.Result, remember bool)
+		// EnableRequiredMod asks whether neededMod should be installed before baseModName.
+		EnableRequiredMod(baseModName mods.ModName, neededMod *mods.Mod) mods.Result
+		// UpdateRequiredMod asks whether the enabled required mod should be updated to update before baseModName is enabled.
+		UpdateRequiredMod(baseModName mods.ModName, required mods.TrackedMod, update *mods.Mod) mods.Result
+		// AllowGameVersion asks whether mod may be enabled although it does not list installed as a supported game version.
+		AllowGameVersion(mod *mods.Mod, installed config.VersionID) mods.Result
+		// Missing7zip tells the user 7-Zip has to be installed from url before game archives that are not zip files
+		// can be changed.
+		Missing7zip(url string) (mods.Result, error)
+	}
+)
+
+var current Prompter
+
+func Get() Prompter {
+	return current
+}
+
+func Set(p Prompter) {
+	current = p
+}
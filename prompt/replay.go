@@ -0,0 +1,79 @@
+package prompt
+
+import (
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+type replay struct {
+	choices  map[mods.ModID]mods.SelectedChoices
+	winners  map[string]mods.ModID
+	fallback Prompter
+}
+
+// NewReplay creates a Prompter that answers with previously recorded configuration choices and conflict winners.
+// Anything that was not recorded is passed on to fallback.
+func NewReplay(choices map[mods.ModID]mods.SelectedChoices, winners map[string]mods.ModID, fallback Prompter) Prompter {
+	return &replay{
+		choices:  choices,
+		winners:  winners,
+		fallback: fallback,
+	}
+}
+
+func (p *replay) Configure(game config.GameDef, mod *mods.Mod, modPath string) (mods.Result, []*mods.Choice, error) {
+	recorded, found := p.choices[mod.ID()]
+	if !found || len(recorded) == 0 {
+		return p.fallback.Configure(game, mod, modPath)
+	}
+	choices, err := walkConfigurations(mod, func(c *mods.Configuration) ([]*mods.Choice, error) {
+		names, ok := recorded[c.Name]
+		if !ok {
+			return nil, errCancelled
+		}
+		return selectByName(c, names)
+	})
+	if err != nil {
+		// The mod's configurations changed since the choices were recorded
+		return p.fallback.Configure(game, mod, modPath)
+	}
+	return mods.Ok, choices, nil
+}
+
+func (p *replay) ConfirmDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (mods.Result, error) {
+	return p.fallback.ConfirmDownloads(game, tm, toInstall)
+}
+
+func (p *replay) ResolveConflicts(mod *mods.Mod, conflicts []*files.Conflict) (mods.Result, bool) {
+	var unresolved []*files.Conflict
+	for _, c := range conflicts {
+		if id, found := p.winners[c.Path]; found && id == mod.ID() {
+			c.Selection = mod
+		} else if found && id == c.Owner.ID() {
+			c.Selection = c.Owner
+		} else {
+			unresolved = append(unresolved, c)
+		}
+	}
+	if len(unresolved) == 0 {
+		return mods.Ok, false
+	}
+	return p.fallback.ResolveConflicts(mod, unresolved)
+}
+
+func (p *replay) EnableRequiredMod(baseModName mods.ModName, neededMod *mods.Mod) mods.Result {
+	return p.fallback.EnableRequiredMod(baseModName, neededMod)
+}
+
+func (p *replay) AllowGameVersion(mod *mods.Mod, installed config.VersionID) mods.Result {
+	return p.fallback.AllowGameVersion(mod, installed)
+}
+
+func (p *replay) UpdateRequiredMod(baseModName mods.ModName, required mods.TrackedMod, update *mods.Mod) mods.Result {
+	return p.fallback.UpdateRequiredMod(baseModName, required, update)
+}
+
+func (p *replay) Missing7zip(url string) (mods.Result, error) {
+	return p.fallback.Missing7zip(url)
+}
@@ -0,0 +1,211 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/downloads"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+type terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewTerminal creates a Prompter that asks its questions on out and reads the answers from in.
+func NewTerminal(in io.Reader, out io.Writer) Prompter {
+	return &terminal{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+func (p *terminal) Configure(_ config.GameDef, mod *mods.Mod, _ string) (mods.Result, []*mods.Choice, error) {
+	var cancelled bool
+	choices, err := walkConfigurations(mod, func(c *mods.Configuration) ([]*mods.Choice, error) {
+		p.printf("\n%s\n", c.Name)
+		if c.Description != "" {
+			p.printf("%s\n", c.Description)
+		}
+		for i, ch := range c.Choices {
+			p.printf("  %d. %s\n", i+1, ch.Name)
+		}
+		for {
+			var (
+				line, err = p.ask("Select", c.SelectionType == mods.Multi)
+				selected  []*mods.Choice
+			)
+			if err != nil {
+				return nil, err
+			}
+			if line == "q" {
+				cancelled = true
+				return nil, errCancelled
+			}
+			if line == "" {
+				return c.Choices[:1], nil
+			}
+			if selected = p.parseChoices(c, line); len(selected) > 0 {
+				return selected, nil
+			}
+			p.printf("Invalid selection\n")
+		}
+	})
+	if cancelled {
+		return mods.Cancel, nil, nil
+	}
+	if err != nil {
+		return mods.Error, nil, err
+	}
+	return mods.Ok, choices, nil
+}
+
+func (p *terminal) parseChoices(c *mods.Configuration, line string) (selected []*mods.Choice) {
+	sp := strings.Split(line, ",")
+	if len(sp) > 1 && c.SelectionType != mods.Multi {
+		return nil
+	}
+	for _, s := range sp {
+		i, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || i < 1 || i > len(c.Choices) {
+			return nil
+		}
+		selected = append(selected, c.Choices[i-1])
+	}
+	return
+}
+
+func (p *terminal) ConfirmDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (mods.Result, error) {
+	if isManualDownload(tm) {
+		return p.confirmManualDownloads(game, tm, toInstall)
+	}
+	k := tm.Kinds()
+	if !k.IsHosted() {
+		return mods.Ok, nil
+	}
+
+	var sources []string
+	for _, ti := range toInstall {
+		if !downloads.IsHostedDownloaded(game, tm, ti) {
+			sources = append(sources, ti.Download.Hosted.Sources...)
+		}
+	}
+	if len(sources) == 0 {
+		return mods.Ok, nil
+	}
+	p.printf("\nThe following files will be downloaded:\n")
+	for _, s := range sources {
+		p.printf("  %s\n", s)
+	}
+	return p.confirm("Download files?")
+}
+
+func (p *terminal) confirmManualDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (mods.Result, error) {
+	for {
+		missing, err := missingManualDownloads(game, tm, toInstall)
+		if err != nil {
+			return mods.Error, err
+		}
+		if len(missing) == 0 {
+			return mods.Ok, nil
+		}
+		p.printf("\nDownload the following file/s:\n")
+		for _, m := range missing {
+			p.printf("  %s\n    place in: %s\n", m.uri, filepath.Join(m.dir, m.fileName))
+		}
+		var line string
+		if line, err = p.ask("Press Enter once downloaded or 'q' to cancel", false); err != nil {
+			return mods.Error, err
+		}
+		if line == "q" {
+			return mods.Cancel, nil
+		}
+	}
+}
+
+func (p *terminal) ResolveConflicts(mod *mods.Mod, conflicts []*files.Conflict) (mods.Result, bool) {
+	var useForRest *mods.Mod
+	p.printf("\n%d file/s are already installed by other mods.\n", len(conflicts))
+	for _, c := range conflicts {
+		if useForRest != nil {
+			c.Selection = useForRest
+			continue
+		}
+		p.printf("%s\n  1. %s (installing)\n  2. %s (current)\n", c.Path, mod.Name, c.Owner.Name)
+		for c.Selection = nil; c.Selection == nil; {
+			line, err := p.ask("Keep [1/2], 'a' to use 1 for all, 'o' to use 2 for all, 'q' to cancel", false)
+			if err != nil {
+				return mods.Cancel, false
+			}
+			switch line {
+			case "", "1":
+				c.Selection = mod
+			case "2":
+				c.Selection = c.Owner
+			case "a":
+				c.Selection = mod
+				useForRest = mod
+			case "o":
+				c.Selection = c.Owner
+				useForRest = c.Owner
+			case "q":
+				return mods.Cancel, false
+			}
+		}
+	}
+	line, err := p.ask("Add these choices to the load order so they are not asked again? [y/N]", false)
+	return mods.Ok, err == nil && strings.HasPrefix(strings.ToLower(line), "y")
+}
+
+func (p *terminal) EnableRequiredMod(baseModName mods.ModName, neededMod *mods.Mod) mods.Result {
+	r, _ := p.confirm(fmt.Sprintf("[%s] requires [%s], would you like to enable it first?", baseModName, neededMod.Name))
+	return r
+}
+
+func (p *terminal) UpdateRequiredMod(baseModName mods.ModName, required mods.TrackedMod, update *mods.Mod) mods.Result {
+	r, _ := p.confirm(fmt.Sprintf("[%s] requires a different version of [%s], would you like to update it from %s to %s first?", baseModName, required.DisplayName(), required.Mod().Version, update.Version))
+	return r
+}
+
+func (p *terminal) AllowGameVersion(mod *mods.Mod, installed config.VersionID) mods.Result {
+	r, _ := p.confirm(fmt.Sprintf("[%s] was not made for the installed game version %s, would you like to enable it anyway?", mod.Name, installed))
+	return r
+}
+
+func (p *terminal) Missing7zip(url string) (mods.Result, error) {
+	return mods.Error, missing7zip(url)
+}
+
+func (p *terminal) confirm(question string) (mods.Result, error) {
+	line, err := p.ask(question+" [Y/n]", false)
+	if err != nil {
+		return mods.Cancel, err
+	}
+	if line == "" || strings.HasPrefix(strings.ToLower(line), "y") {
+		return mods.Ok, nil
+	}
+	return mods.Cancel, nil
+}
+
+func (p *terminal) ask(question string, multi bool) (string, error) {
+	if multi {
+		question += " (comma separated)"
+	}
+	p.printf("%s: ", question)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read answer: %v", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (p *terminal) printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(p.out, format, a...)
+}
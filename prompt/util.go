@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/downloads"
+	"github.com/kiamev/moogle-mod-manager/mods"
+)
+
+type manualDownload struct {
+	uri      string
+	dir      string
+	fileName string
+}
+
+// walkConfigurations follows a mod's configurations from the root the same way the config installer screen does.
+func walkConfigurations(mod *mods.Mod, selectChoices func(c *mods.Configuration) ([]*mods.Choice, error)) ([]*mods.Choice, error) {
+	var (
+		choices  []*mods.Choice
+		selected []*mods.Choice
+		visited  = make(map[string]bool)
+		c, err   = mod.RootConfiguration()
+	)
+	if err != nil {
+		return nil, err
+	}
+	for c != nil {
+		if visited[c.Name] {
+			return nil, fmt.Errorf("configuration [%s] is part of a loop", c.Name)
+		}
+		visited[c.Name] = true
+
+		if selected, err = selectChoices(c); err != nil {
+			return nil, err
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("no choice selected for configuration [%s]", c.Name)
+		}
+		choices = append(choices, selected...)
+
+		if c.SelectionType == mods.Multi || selected[0].NextConfigurationName == nil {
+			break
+		}
+		next := *selected[0].NextConfigurationName
+		if c = mod.GetConfiguration(next); c == nil {
+			return nil, fmt.Errorf("configuration [%s] not found", next)
+		}
+	}
+	return choices, nil
+}
+
+func selectByName(c *mods.Configuration, names []string) (selected []*mods.Choice, err error) {
+	for _, n := range names {
+		ch := c.GetChoice(n)
+		if ch == nil {
+			return nil, fmt.Errorf("configuration [%s] has no choice [%s]", c.Name, n)
+		}
+		selected = append(selected, ch)
+	}
+	if len(selected) > 1 && c.SelectionType != mods.Multi {
+		return nil, fmt.Errorf("configuration [%s] only allows one choice", c.Name)
+	}
+	return
+}
+
+func isManualDownload(tm mods.TrackedMod) bool {
+	k := tm.Kinds()
+	return k.Is(mods.Nexus) || k.Is(mods.GoogleDrive)
+}
+
+// missingManualDownloads returns the files that have to be downloaded by the user before the install can continue.
+func missingManualDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (missing []manualDownload, err error) {
+	for _, ti := range toInstall {
+		if ti.Download == nil {
+			continue
+		}
+		dl := manualDownload{}
+		dl.fileName, _ = ti.Download.FileName()
+		if dl.uri, err = downloads.DownloadLink(game, ti.Download); err != nil {
+			return
+		}
+		if dl.dir, err = ti.GetDownloadLocation(game, tm); err != nil {
+			return
+		}
+		if _, err = os.Stat(filepath.Join(dl.dir, dl.fileName)); err == nil {
+			continue
+		}
+		err = nil
+		missing = append(missing, dl)
+	}
+	return
+}
+
+var errCancelled = errors.New("cancelled")
+
+func missing7zip(url string) error {
+	return fmt.Errorf("7-Zip is needed to change non-zip game archives, install it from %s and add it to the path", url)
+}
@@ -0,0 +1,466 @@
+package snapshots
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+const (
+	dir        = "snapshots"
+	objectsDir = "objects"
+	// maxAutomatic is how many automatic snapshots are kept for each game, the oldest are deleted first
+	maxAutomatic = 10
+)
+
+type (
+	// Snapshot is the state of every file the manager tracks for a game, and of the game's mods, at a point in time.
+	// File contents are stored once by their SHA-256 however many snapshots hold them.
+	Snapshot struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Created   time.Time `json:"created"`
+		Automatic bool      `json:"automatic,omitempty"`
+		// Files maps every installed file and backup to the hash of its content, empty when the file was missing
+		Files map[string]string `json:"files"`
+		// Archives maps each archive with injected files to the hash of every injected entry. Only the entries are
+		// stored as the rest of the archive is what the backups put back.
+		Archives map[string]map[string]string `json:"archives,omitempty"`
+		// Tracker is the game's part of filetracker.json
+		Tracker json.RawMessage `json:"tracker"`
+		Mods    []*modState     `json:"mods"`
+	}
+	modState struct {
+		ID mods.ModID `json:"id"`
+		// Mod is the hash of the mod's definition
+		Mod     string               `json:"mod"`
+		Enabled bool                 `json:"enabled"`
+		Choices mods.SelectedChoices `json:"choices,omitempty"`
+	}
+)
+
+// Take snapshots the game. Automatic snapshots are the ones taken before batch actions, only the latest
+// maxAutomatic of them are kept along with the ones whose ids are in keep.
+func Take(game config.GameDef, name string, automatic bool, keep ...string) (s *Snapshot, err error) {
+	s = &Snapshot{
+		Name:      name,
+		Created:   time.Now(),
+		Automatic: automatic,
+		Files:     make(map[string]string),
+		Archives:  make(map[string]map[string]string),
+	}
+	var (
+		paths    []string
+		archives map[string][]string
+	)
+	if paths, archives, err = trackedPaths(game); err != nil {
+		return
+	}
+	for _, p := range paths {
+		if _, err = os.Stat(p); errors.Is(err, os.ErrNotExist) {
+			s.Files[p] = ""
+			continue
+		}
+		if s.Files[p], err = storeFile(game, p); err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %v", p, err)
+		}
+	}
+	for a, entries := range archives {
+		if s.Archives[a], err = storeEntries(game, a, entries); err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %v", a, err)
+		}
+	}
+	if s.Tracker, err = files.TrackerState(game); err != nil {
+		return nil, fmt.Errorf("failed to snapshot the file tracker: %v", err)
+	}
+	for _, ms := range managed.ModStates(game) {
+		var b []byte
+		if b, err = json.Marshal(ms.Mod.ModDef); err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %v", ms.Mod.ID(), err)
+		}
+		st := &modState{ID: ms.Mod.ID(), Enabled: ms.Enabled, Choices: ms.Choices}
+		if st.Mod, err = store(game, b); err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %v", ms.Mod.ID(), err)
+		}
+		s.Mods = append(s.Mods, st)
+	}
+
+	s.ID = s.Created.Format("20060102-150405")
+	for i := 2; util.FileExists(manifest(game, s.ID)); i++ {
+		s.ID = fmt.Sprintf("%s-%d", s.Created.Format("20060102-150405"), i)
+	}
+	if err = util.SaveToFile(manifest(game, s.ID), s); err != nil {
+		return nil, err
+	}
+	if automatic {
+		err = prune(game, keep)
+	}
+	return
+}
+
+// List returns the game's snapshots, newest first. Snapshots whose manifest cannot be read are left out and reported
+// in damaged, so one bad manifest does not hide the others or stop automatic snapshots from being taken.
+func List(game config.GameDef) (snapshots []*Snapshot, damaged []error, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(gameDir(game)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	for _, de := range entries {
+		if de.IsDir() || filepath.Ext(de.Name()) != ".json" {
+			continue
+		}
+		if s, e := Get(game, strings.TrimSuffix(de.Name(), ".json")); e != nil {
+			damaged = append(damaged, e)
+		} else {
+			snapshots = append(snapshots, s)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Created.After(snapshots[j].Created) })
+	return
+}
+
+// Get returns the game's snapshot with the id.
+func Get(game config.GameDef, id string) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := util.LoadFromFile(manifest(game, id), s); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("snapshot [%s] not found", id)
+		}
+		return nil, fmt.Errorf("failed to load snapshot [%s]: %v", id, err)
+	}
+	return s, nil
+}
+
+// Latest returns the game's newest snapshot that can be read, nil when there are none.
+func Latest(game config.GameDef) (*Snapshot, error) {
+	snapshots, _, err := List(game)
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	return snapshots[0], nil
+}
+
+// Delete removes the game's snapshot with the id along with the stored files no other snapshot holds.
+func Delete(game config.GameDef, id string) error {
+	if err := os.Remove(manifest(game, id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("snapshot [%s] not found", id)
+		}
+		return fmt.Errorf("failed to delete snapshot [%s]: %v", id, err)
+	}
+	return collectGarbage(game)
+}
+
+// Check makes sure every file the snapshot holds is still stored, so restoring it will not stop half-way.
+func (s *Snapshot) Check(game config.GameDef) error {
+	for _, h := range s.hashes() {
+		if !util.FileExists(object(game, h)) {
+			return fmt.Errorf("snapshot [%s] is damaged, a stored file is missing", s.ID)
+		}
+	}
+	return nil
+}
+
+// Restore puts the snapshot's files, file tracker state and mods back. It is meant to run once the game is vanilla,
+// as files the snapshot did not track are left as they are.
+func Restore(game config.GameDef, s *Snapshot) (err error) {
+	if err = s.Check(game); err != nil {
+		return
+	}
+	for p, h := range s.Files {
+		if h == "" {
+			if err = os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %v", p, err)
+			}
+			continue
+		}
+		if current, e := util.HashFile(p); e == nil && current == h {
+			continue
+		}
+		if err = copyFile(object(game, h), p); err != nil {
+			return fmt.Errorf("failed to restore %s: %v", p, err)
+		}
+	}
+	for a, entries := range s.Archives {
+		update := make(map[string]string, len(entries))
+		for e, h := range entries {
+			update[e] = object(game, h)
+		}
+		if err = archive.UpdateEntries(a, update); err != nil {
+			return fmt.Errorf("failed to restore %s: %v", a, err)
+		}
+	}
+	if err = files.SetTrackerState(game, s.Tracker); err != nil {
+		return
+	}
+	states := make([]*managed.ModState, len(s.Mods))
+	for i, ms := range s.Mods {
+		mod := &mods.Mod{}
+		if err = loadMod(object(game, ms.Mod), mod); err != nil {
+			return fmt.Errorf("failed to restore %s: %v", ms.ID, err)
+		}
+		states[i] = &managed.ModState{Mod: mod, Enabled: ms.Enabled, Choices: ms.Choices}
+	}
+	return managed.SetModStates(game, states)
+}
+
+func (s *Snapshot) String() string {
+	kind := "manual"
+	if s.Automatic {
+		kind = "automatic"
+	}
+	count := len(s.Files)
+	for _, entries := range s.Archives {
+		count += len(entries)
+	}
+	return fmt.Sprintf("%s - %s (%s, %s, %d files)", s.ID, s.Name, s.Created.Format("2006-01-02 15:04:05"), kind, count)
+}
+
+func (s *Snapshot) hashes() (hashes []string) {
+	for _, h := range s.Files {
+		if h != "" {
+			hashes = append(hashes, h)
+		}
+	}
+	for _, entries := range s.Archives {
+		for _, h := range entries {
+			hashes = append(hashes, h)
+		}
+	}
+	for _, ms := range s.Mods {
+		hashes = append(hashes, ms.Mod)
+	}
+	return
+}
+
+// trackedPaths returns every file the file tracker knows of, the mods' files and the backups, and the entries the
+// mods injected into each archive.
+func trackedPaths(game config.GameDef) (paths []string, archives map[string][]string, err error) {
+	var (
+		mt   = files.ModTracker(game)
+		seen = make(map[string]bool)
+		root string
+	)
+	archives = make(map[string][]string)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, ft := range mt.Mods {
+		for _, f := range ft.Files.Keys() {
+			add(f)
+		}
+		for a, entries := range ft.ArchiveFiles {
+			if root == "" {
+				if root, err = config.Get().GetDir(game, config.GameDirKind); err != nil {
+					return
+				}
+			}
+			abs := filepath.Join(root, a)
+			archives[abs] = append(archives[abs], entries.Keys()...)
+		}
+	}
+	for bu := range mt.Backups {
+		add(bu)
+	}
+	sort.Strings(paths)
+	return
+}
+
+// prune deletes the oldest automatic snapshots beyond maxAutomatic, other than the ones in keep.
+func prune(game config.GameDef, keep []string) error {
+	snapshots, _, err := List(game)
+	if err != nil {
+		return err
+	}
+	var (
+		kept    int
+		deleted bool
+	)
+	for _, s := range snapshots {
+		if !s.Automatic || contains(keep, s.ID) {
+			continue
+		}
+		if kept++; kept > maxAutomatic {
+			if err = os.Remove(manifest(game, s.ID)); err != nil {
+				return fmt.Errorf("failed to delete snapshot [%s]: %v", s.ID, err)
+			}
+			deleted = true
+		}
+	}
+	if deleted {
+		return collectGarbage(game)
+	}
+	return nil
+}
+
+// collectGarbage removes the stored files no snapshot holds. Nothing is removed while a snapshot cannot be read, as
+// the files it holds are not known.
+func collectGarbage(game config.GameDef) error {
+	snapshots, damaged, err := List(game)
+	if err != nil || len(damaged) > 0 {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, s := range snapshots {
+		for _, h := range s.hashes() {
+			used[h] = true
+		}
+	}
+	return filepath.WalkDir(filepath.Join(gameDir(game), objectsDir), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && !used[d.Name()] {
+			if err = os.Remove(path); err != nil {
+				return fmt.Errorf("failed to delete %s: %v", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// storeFile stores the content of file unless a snapshot already did, returning its hash.
+func storeFile(game config.GameDef, file string) (hash string, err error) {
+	if hash, err = util.HashFile(file); err != nil {
+		return
+	}
+	if util.FileExists(object(game, hash)) {
+		return
+	}
+	err = copyFile(file, object(game, hash))
+	return
+}
+
+// storeEntries stores the archive's entries, returning the hash of each.
+func storeEntries(game config.GameDef, arch string, entries []string) (hashes map[string]string, err error) {
+	if err = os.MkdirAll(gameDir(game), 0777); err != nil {
+		return
+	}
+	var tmp string
+	if tmp, err = os.MkdirTemp(gameDir(game), "entries"); err != nil {
+		return
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	hashes = make(map[string]string, len(entries))
+	for i, e := range entries {
+		if _, found := hashes[e]; found {
+			continue
+		}
+		f := filepath.Join(tmp, strconv.Itoa(i))
+		if err = archive.ExtractEntry(arch, e, f); err != nil {
+			return
+		}
+		if hashes[e], err = storeFile(game, f); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// store stores b unless a snapshot already did, returning its hash.
+func store(game config.GameDef, b []byte) (hash string, err error) {
+	if hash, err = util.Hash(bytes.NewReader(b)); err != nil {
+		return
+	}
+	o := object(game, hash)
+	if util.FileExists(o) {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(o), 0777); err != nil {
+		return
+	}
+	err = os.WriteFile(o, b, 0644)
+	return
+}
+
+// copyFile copies from, and its permissions, to a temporary file next to to and then renames it, so to is never left
+// half-written.
+func copyFile(from string, to string) (err error) {
+	var (
+		src *os.File
+		dst *os.File
+		fi  os.FileInfo
+	)
+	if src, err = os.Open(from); err != nil {
+		return
+	}
+	defer func() { _ = src.Close() }()
+	if fi, err = src.Stat(); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(to), 0777); err != nil {
+		return
+	}
+	if dst, err = os.CreateTemp(filepath.Dir(to), filepath.Base(to)+".*.tmp"); err != nil {
+		return
+	}
+	if _, err = io.Copy(dst, src); err == nil {
+		err = dst.Chmod(fi.Mode().Perm())
+	}
+	if err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return
+	}
+	if err = dst.Close(); err != nil {
+		_ = os.Remove(dst.Name())
+		return
+	}
+	if err = os.Rename(dst.Name(), to); err != nil {
+		_ = os.Remove(dst.Name())
+	}
+	return
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func loadMod(file string, mod *mods.Mod) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, mod)
+}
+
+func gameDir(game config.GameDef) string {
+	return filepath.Join(config.PWD, dir, string(game.ID()))
+}
+
+func manifest(game config.GameDef, id string) string {
+	return filepath.Join(gameDir(game), id+".json")
+}
+
+func object(game config.GameDef, hash string) string {
+	return filepath.Join(gameDir(game), objectsDir, hash[:2], hash)
+}
@@ -0,0 +1,194 @@
+package snapshots
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiamev/moogle-mod-manager/archive"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/config/configtest"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/util"
+)
+
+// setup creates a game with the file a.txt installed by mod, its backup and the entry e.txt mod injected into data.zip.
+func setup(t *testing.T) (game config.GameDef, gameDir string) {
+	game = configtest.Game(t)
+	gameDir = filepath.Join(config.PWD, "game")
+	config.Get().GameDirs[string(game.ID())] = &config.GameDir{Dir: gameDir}
+	files.RemoveAllFilesForGame(game)
+
+	write(t, filepath.Join(gameDir, "a.txt"), "mod")
+	write(t, filepath.Join(config.PWD, "backups", "a.txt"), "vanilla")
+	write(t, filepath.Join(config.PWD, "entry.txt"), "entry")
+	if err := archive.ZipCreate(filepath.Join(gameDir, "data.zip"), map[string]string{"e.txt": filepath.Join(config.PWD, "entry.txt")}); err != nil {
+		t.Fatal(err)
+	}
+	files.SetFiles(game, "mod", filepath.Join(gameDir, "a.txt"))
+	files.SetBackup(game, filepath.Join(config.PWD, "backups", "a.txt"), filepath.Join(gameDir, "a.txt"), "", "")
+	files.AppendArchiveFiles(game, "mod", "data.zip", "e.txt")
+	return
+}
+
+func TestTakeRestore(t *testing.T) {
+	game, gameDir := setup(t)
+	var (
+		file   = filepath.Join(gameDir, "a.txt")
+		backup = filepath.Join(config.PWD, "backups", "a.txt")
+		arch   = filepath.Join(gameDir, "data.zip")
+	)
+	s, err := Take(game, "before", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write(t, file, "changed")
+	if err = os.Remove(backup); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(config.PWD, "other.txt"), "other")
+	if err = archive.ZipUpdate(arch, map[string]string{"e.txt": filepath.Join(config.PWD, "other.txt")}); err != nil {
+		t.Fatal(err)
+	}
+	files.RemoveAllFilesForMod(game, "mod")
+
+	if err = Restore(game, s); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, file); got != "mod" {
+		t.Errorf("a.txt = %q, want %q", got, "mod")
+	}
+	if got := read(t, backup); got != "vanilla" {
+		t.Errorf("backup = %q, want %q", got, "vanilla")
+	}
+	if err = archive.ZipExtract(arch, "e.txt", filepath.Join(config.PWD, "out.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, filepath.Join(config.PWD, "out.txt")); got != "entry" {
+		t.Errorf("e.txt = %q, want %q", got, "entry")
+	}
+	if f := files.Files(game, "mod"); !f.Contains(file) {
+		t.Error("a.txt is no longer tracked for mod")
+	}
+}
+
+func TestDedup(t *testing.T) {
+	game, gameDir := setup(t)
+	// The backup and the second file hold the same content as a.txt and its backup
+	write(t, filepath.Join(gameDir, "b.txt"), "vanilla")
+	files.SetFiles(game, "mod", filepath.Join(gameDir, "b.txt"))
+	for i := 0; i < 2; i++ {
+		if _, err := Take(game, "snapshot", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "mod", "vanilla" and "entry", the mods' definitions are not stored without mods
+	if n := objects(t, game); n != 3 {
+		t.Errorf("%d objects stored, want 3", n)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	game, _ := setup(t)
+	manual, err := Take(game, "manual", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids, keep []string
+	for i := 0; i < maxAutomatic+3; i++ {
+		s, err := Take(game, "automatic", true, keep...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, s.ID)
+		keep = ids[:1]
+	}
+	l, damaged, err := List(game)
+	if err != nil || len(damaged) > 0 {
+		t.Fatal(err, damaged)
+	}
+	want := map[string]bool{manual.ID: true, ids[0]: true}
+	for _, id := range ids[len(ids)-maxAutomatic:] {
+		want[id] = true
+	}
+	if len(l) != len(want) {
+		t.Errorf("%d snapshots kept, want %d", len(l), len(want))
+	}
+	for _, s := range l {
+		if !want[s.ID] {
+			t.Errorf("snapshot %s was kept", s.ID)
+		}
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	game, gameDir := setup(t)
+	first, err := Take(game, "first", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(gameDir, "a.txt"), "changed")
+	if _, err = Take(game, "second", false); err != nil {
+		t.Fatal(err)
+	}
+	if n := objects(t, game); n != 4 {
+		t.Fatalf("%d objects stored, want 4", n)
+	}
+
+	// A manifest that cannot be read keeps every object, as what it holds is not known
+	write(t, manifest(game, "damaged"), "{")
+	if err = Delete(game, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := objects(t, game); n != 4 {
+		t.Errorf("%d objects stored with a damaged snapshot, want 4", n)
+	}
+	l, damaged, err := List(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 1 || len(damaged) != 1 {
+		t.Errorf("List() = %d snapshots and %d damaged, want 1 and 1", len(l), len(damaged))
+	}
+
+	if err = Delete(game, "damaged"); err != nil {
+		t.Fatal(err)
+	}
+	// Only the first snapshot held "mod"
+	if n := objects(t, game); n != 3 {
+		t.Errorf("%d objects stored, want 3", n)
+	}
+}
+
+func objects(t *testing.T, game config.GameDef) (n int) {
+	if err := filepath.WalkDir(filepath.Join(gameDir(game), objectsDir), func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func write(t *testing.T, file, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, file string) string {
+	if !util.FileExists(file) {
+		t.Fatalf("%s is missing", file)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -1,9 +1,6 @@
 package config_installer
 
 import (
-	"errors"
-	"fmt"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
@@ -14,7 +11,7 @@ import (
 
 type ConfigInstaller interface {
 	state.Screen
-	Setup(mod *mods.Mod, baseDir string, done func(mods.Result, []*mods.ToInstall) error) error
+	Setup(mod *mods.Mod, baseDir string, done func(mods.Result, []*mods.Choice) error) error
 }
 
 func New() ConfigInstaller {
@@ -29,7 +26,7 @@ type configInstallerUI struct {
 	choices         []*mods.Choice
 	choiceContainer *fyne.Container
 	baseDir         string
-	done            func(mods.Result, []*mods.ToInstall) error
+	done            func(mods.Result, []*mods.Choice) error
 
 	currentConfig  *mods.Configuration
 	currentChoices []*mods.Choice
@@ -41,19 +38,12 @@ func (ui *configInstallerUI) OnClose() {}
 
 func (ui *configInstallerUI) DrawAsDialog(fyne.Window) {}
 
-func (ui *configInstallerUI) Setup(mod *mods.Mod, baseDir string, done func(mods.Result, []*mods.ToInstall) error) error {
-	if len(mod.Configurations) == 0 || len(mod.Configurations[0].Choices) == 0 {
-		return fmt.Errorf("no configurations for %s", mod.Name)
+func (ui *configInstallerUI) Setup(mod *mods.Mod, baseDir string, done func(mods.Result, []*mods.Choice) error) error {
+	var err error
+	if ui.currentConfig, err = mod.RootConfiguration(); err != nil {
+		return err
 	}
 	ui.mod = mod
-	for _, ui.currentConfig = range mod.Configurations {
-		if ui.currentConfig.Root {
-			break
-		}
-	}
-	if ui.currentConfig == nil || !ui.currentConfig.Root {
-		return errors.New("could not find root configuration")
-	}
 	ui.prevConfigs = make([]*mods.Configuration, 0)
 	ui.baseDir = baseDir
 	ui.done = done
@@ -75,14 +65,8 @@ func (ui *configInstallerUI) Draw(w fyne.Window) {
 			}
 
 			if ui.currentConfig.SelectionType == mods.Multi || ui.currentChoices[0].NextConfigurationName == nil {
-				tis, err := mods.NewToInstallForMod(ui.mod, ui.uniqueToInstall())
-				if err != nil {
-					util.ShowErrorLong(err)
-					state.ShowPreviousScreen()
-					return
-				}
 				state.ShowPreviousScreen()
-				if err = ui.done(mods.Ok, tis); err != nil {
+				if err := ui.done(mods.Ok, ui.choices); err != nil {
 					util.ShowErrorLong(err)
 					return
 				}
@@ -197,63 +181,3 @@ func (ui *configInstallerUI) popChoice() (c *mods.Configuration) {
 	ui.prevConfigs = ui.prevConfigs[:l]
 	return
 }
-
-func (ui *configInstallerUI) uniqueToInstall() []*mods.DownloadFiles {
-	var (
-		l  = make(map[string]*mods.DownloadFiles)
-		df *mods.DownloadFiles
-	)
-	for _, df = range ui.mod.AlwaysDownload {
-		l[df.DownloadName] = df
-	}
-	for _, c := range ui.choices {
-		df = c.DownloadFiles
-		if df != nil && df.DownloadName != "" {
-			if len(df.Dirs) > 0 || len(df.Files) > 0 {
-				if to, found := l[df.DownloadName]; !found {
-					l[df.DownloadName] = df
-				} else {
-					l[df.DownloadName] = merge(to, df)
-				}
-			}
-		}
-	}
-	result := make([]*mods.DownloadFiles, 0, len(l))
-	for _, df = range l {
-		result = append(result, df)
-	}
-	return result
-}
-
-func merge(df1 *mods.DownloadFiles, df2 *mods.DownloadFiles) *mods.DownloadFiles {
-	var (
-		m     = make(map[string]bool)
-		dirs  = make([]*mods.ModDir, 0, len(df1.Dirs)+len(df2.Dirs))
-		files = make([]*mods.ModFile, 0, len(df1.Files)+len(df2.Files))
-	)
-	for _, d := range df2.Dirs {
-		m[d.To] = true
-		dirs = append(dirs, d)
-	}
-	for _, d := range df1.Dirs {
-		if !m[d.To] {
-			dirs = append(dirs, d)
-		}
-	}
-
-	m = make(map[string]bool)
-	for _, f := range df2.Files {
-		m[f.To] = true
-		files = append(files, f)
-	}
-	for _, f := range df1.Files {
-		if !m[f.To] {
-			files = append(files, f)
-		}
-	}
-	return &mods.DownloadFiles{
-		DownloadName: df1.DownloadName,
-		Dirs:         dirs,
-		Files:        files,
-	}
-}
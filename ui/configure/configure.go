@@ -1,7 +1,10 @@
 package configure
 
 import (
+	"errors"
 	"os"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -19,6 +22,9 @@ func Show(w fyne.Window, done func()) {
 		createSelectRow("Default GameDef", &configs.DefaultGame, config.GameIDs()...),
 		createCheckboxRow("Check For M3 Updates on Start", configs.CheckForM3UpdateOnStart),
 		createCheckboxRow("Delete Downloads After Install", &configs.DeleteDownloadAfterInstall),
+		createCheckboxRow("Block Mods Made For Other Game Versions", &configs.BlockWrongGameVersion),
+		createIntRow("Download Timeout (seconds)", &configs.DownloadTimeout),
+		createIntRow("Resume Stalled Downloads After (seconds)", &configs.DownloadStallTimeout),
 	}
 	for _, g := range config.GameDefs() {
 		var (
@@ -71,6 +77,18 @@ func createCheckboxRow(label string, value *bool, options ...string) *widget.For
 	return widget.NewFormItem(label, widget.NewCheckWithData("", binding.BindBool(value)))
 }
 
+// createIntRow edits a whole number above zero, the form cannot be saved while the row holds anything else.
+func createIntRow(label string, value *int) *widget.FormItem {
+	e := widget.NewEntryWithData(binding.IntToString(binding.BindInt(value)))
+	e.Validator = func(s string) error {
+		if i, err := strconv.Atoi(strings.TrimSpace(s)); err != nil || i <= 0 {
+			return errors.New("must be a whole number above 0")
+		}
+		return nil
+	}
+	return widget.NewFormItem(label, e)
+}
+
 func createDirRow(label string, value *string) *widget.FormItem {
 	b := binding.BindString(value)
 	o := &cw.OpenDirDialog{
@@ -24,3 +24,17 @@ func ShowEnableModConfirmDialog(baseModName mods.ModName, neededMod *mods.Mod, d
 	d.Show()
 	return
 }
+
+func ShowUpdateModConfirmDialog(baseModName mods.ModName, required mods.TrackedMod, update *mods.Mod, done func(mods.Result)) {
+	msg := fmt.Sprintf("[%s] requires a different version of [%s], would you like to update it from %s to %s first?", baseModName, required.DisplayName(), required.Mod().Version, update.Version)
+	d := dialog.NewCustomConfirm("Update Required Mod?", "Yes", "Cancel",
+		container.NewVScroll(widget.NewRichTextFromMarkdown(msg)), func(ok bool) {
+			result := mods.Ok
+			if !ok {
+				result = mods.Cancel
+			}
+			done(result)
+		}, ui.Window)
+	d.Resize(fyne.NewSize(500, 400))
+	d.Show()
+}
@@ -0,0 +1,26 @@
+package confirm
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
+)
+
+func ShowGameVersionConfirmDialog(mod *mods.Mod, installed config.VersionID, done func(mods.Result)) {
+	msg := fmt.Sprintf("[%s] was not made for the installed game version %s and may not work, would you like to enable it anyway?", mod.Name, installed)
+	d := dialog.NewCustomConfirm("Different Game Version", "Yes", "Cancel",
+		container.NewVScroll(widget.NewRichTextFromMarkdown(msg)), func(ok bool) {
+			result := mods.Ok
+			if !ok {
+				result = mods.Cancel
+			}
+			done(result)
+		}, ui.Window)
+	d.Resize(fyne.NewSize(500, 400))
+	d.Show()
+}
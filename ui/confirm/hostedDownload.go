@@ -6,10 +6,9 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/downloads"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
@@ -24,7 +23,7 @@ func newHostedConfirmer(params Params) Confirmer {
 func (c *hostedConfirmer) Downloads(done func(mods.Result)) (err error) {
 	var sb = strings.Builder{}
 	for i, ti := range c.ToInstall {
-		if c.alreadyDownloaded(ti) {
+		if downloads.IsHostedDownloaded(c.Game, c.Mod, ti) {
 			continue
 		}
 		sb.WriteString(fmt.Sprintf("## Download %d\n\n", i+1))
@@ -53,11 +52,3 @@ func (c *hostedConfirmer) Downloads(done func(mods.Result)) (err error) {
 	d.Show()
 	return
 }
-
-func (c *hostedConfirmer) alreadyDownloaded(ti *mods.ToInstall) bool {
-	file := strings.Split(ti.Download.Hosted.Sources[0], "/")
-	file = strings.Split(file[len(file)-1], "?")
-	dir, _ := ti.GetDownloadLocation(c.Game, c.Mod)
-	_, err := os.Stat(filepath.Join(dir, file[0]))
-	return err == nil
-}
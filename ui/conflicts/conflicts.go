@@ -11,17 +11,20 @@ import (
 	"path/filepath"
 )
 
-func ShowConflicts(mod *mods.Mod, conflicts []*files.Conflict, done func(mods.Result)) {
+// ShowConflicts asks which mod keeps each conflicting file. done is also told if the choices should be remembered in
+// the game's load order.
+func ShowConflicts(mod *mods.Mod, conflicts []*files.Conflict, done func(result mods.Result, remember bool)) {
 	f := widget.NewForm()
 	for _, c := range conflicts {
 		f.Items = append(f.Items, createItem(mod, c))
 	}
-	d := dialog.NewCustomConfirm("Conflicts", "ok", "cancel", container.NewVScroll(f), func(ok bool) {
+	remember := widget.NewCheck("Remember in the load order", nil)
+	d := dialog.NewCustomConfirm("Conflicts", "ok", "cancel", container.NewBorder(nil, remember, nil, nil, container.NewVScroll(f)), func(ok bool) {
 		r := mods.Ok
 		if !ok {
 			r = mods.Cancel
 		}
-		done(r)
+		done(r, ok && remember.Checked)
 	}, ui.Window)
 	d.Resize(fyne.NewSize(400, 400))
 	d.Show()
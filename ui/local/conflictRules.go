@@ -0,0 +1,145 @@
+package local
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/conflictrules"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+var ruleKindNames = map[conflictrules.Kind]string{
+	conflictrules.Wins:   "Mod always wins over another mod",
+	conflictrules.Prefer: "Mod wins files matching a pattern",
+	conflictrules.Keep:   "Never overwrite the mod's files",
+}
+
+func (ui *localUI) showConflictRules() {
+	var (
+		rules    = conflictrules.Get(state.CurrentGame)
+		selected = -1
+		list     *widget.List
+	)
+	move := func(by int) {
+		if to := selected + by; selected >= 0 && to >= 0 && to < len(rules) {
+			rules[selected], rules[to] = rules[to], rules[selected]
+			list.Select(to)
+			list.Refresh()
+		}
+	}
+	list = widget.NewList(
+		func() int { return len(rules) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(ruleText(rules[id]))
+		})
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	d := dialog.NewCustomConfirm("Conflict Rules", "Save", "Cancel",
+		container.NewBorder(
+			widget.NewLabel("Rules settle file conflicts without asking. The first rule that applies decides."),
+			container.NewHBox(
+				widget.NewButton("Add", func() {
+					addConflictRule(func(r *conflictrules.Rule) {
+						rules = append(rules, r)
+						list.Refresh()
+					})
+				}),
+				widget.NewButton("Remove", func() {
+					if selected >= 0 && selected < len(rules) {
+						rules = append(rules[:selected], rules[selected+1:]...)
+						list.UnselectAll()
+						list.Refresh()
+					}
+				}),
+				widget.NewButton("Up", func() { move(-1) }),
+				widget.NewButton("Down", func() { move(1) })),
+			nil, nil, list),
+		func(ok bool) {
+			if ok {
+				if err := conflictrules.Set(state.CurrentGame, rules); err != nil {
+					util.ShowErrorLong(err)
+				}
+			}
+		}, u.Window)
+	d.Resize(fyne.NewSize(600, 500))
+	d.Show()
+}
+
+func addConflictRule(added func(r *conflictrules.Rule)) {
+	var (
+		names    []string
+		modNames []string
+		kinds    = make(map[string]conflictrules.Kind)
+		ids      = make(map[string]mods.ModID)
+	)
+	for _, k := range conflictrules.Kinds {
+		names = append(names, ruleKindNames[k])
+		kinds[ruleKindNames[k]] = k
+	}
+	for _, tm := range mods.SortTracked(managed.GetMods(state.CurrentGame)) {
+		modNames = append(modNames, tm.DisplayName())
+		ids[tm.DisplayName()] = tm.ID()
+	}
+	var (
+		other   = widget.NewSelect(modNames, nil)
+		pattern = widget.NewEntry()
+		mod     = widget.NewSelect(modNames, nil)
+		kind    = widget.NewSelect(names, func(s string) {
+			other.Disable()
+			pattern.Disable()
+			switch kinds[s] {
+			case conflictrules.Wins:
+				other.Enable()
+			case conflictrules.Prefer:
+				pattern.Enable()
+			}
+		})
+	)
+	pattern.SetPlaceHolder("e.g. **/StreamingAssets/Sound/**")
+	kind.SetSelectedIndex(0)
+	dialog.ShowForm("Add Conflict Rule", "Add", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Rule", kind),
+			widget.NewFormItem("Mod", mod),
+			widget.NewFormItem("Over Mod", other),
+			widget.NewFormItem("Pattern", pattern),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			r := &conflictrules.Rule{
+				Kind: kinds[kind.Selected],
+				Mod:  ids[mod.Selected],
+			}
+			switch r.Kind {
+			case conflictrules.Wins:
+				r.Other = ids[other.Selected]
+			case conflictrules.Prefer:
+				r.Pattern = pattern.Text
+			}
+			if err := r.Validate(); err != nil {
+				util.ShowErrorLong(err)
+				return
+			}
+			added(r)
+		}, u.Window)
+}
+
+// ruleText describes r with the mods' display names.
+func ruleText(r *conflictrules.Rule) string {
+	c := *r
+	for _, id := range []*mods.ModID{&c.Mod, &c.Other} {
+		if tm, found := managed.TryGetMod(state.CurrentGame, *id); found {
+			*id = mods.ModID(tm.DisplayName())
+		}
+	}
+	return c.String()
+}
@@ -0,0 +1,60 @@
+package local
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) showConflicts() {
+	contested, err := files.Overview(state.CurrentGame)
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	if len(contested) == 0 {
+		dialog.ShowInformation("Conflicts", "No file is wanted by more than one mod.", u.Window)
+		return
+	}
+	list := widget.NewList(
+		func() int { return len(contested) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(contestedText(contested[id]))
+		})
+	d := dialog.NewCustom("Conflicts", "Close",
+		container.NewBorder(
+			widget.NewLabel("Files more than one mod installed or wanted, which mod won and which lost."),
+			nil, nil, nil, list),
+		u.Window)
+	d.Resize(fyne.NewSize(800, 500))
+	d.Show()
+}
+
+// contestedText describes c with the mods' display names.
+func contestedText(c *files.Contested) string {
+	var (
+		cc   = *c
+		name = func(id mods.ModID) mods.ModID {
+			if tm, found := managed.TryGetMod(state.CurrentGame, id); found {
+				return mods.ModID(tm.DisplayName())
+			}
+			return id
+		}
+	)
+	if cc.Winner != "" {
+		cc.Winner = name(cc.Winner)
+	}
+	cc.Losers = make([]mods.ModID, len(c.Losers))
+	for i, id := range c.Losers {
+		cc.Losers[i] = name(id)
+	}
+	return cc.String()
+}
@@ -0,0 +1,229 @@
+package local
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/launch"
+	cw "github.com/kiamev/moogle-mod-manager/ui/custom-widgets"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) newLaunchButton() *cw.ButtonWithPopups {
+	return cw.NewButtonWithPopups("Launch Game",
+		fyne.NewMenuItem("Launch", func() {
+			launchGame(launch.Default(state.CurrentGame))
+		}),
+		fyne.NewMenuItem("Launch With", func() {
+			var (
+				profiles = launch.Profiles(state.CurrentGame)
+				names    = make([]string, len(profiles))
+			)
+			for i, p := range profiles {
+				names[i] = p.Name
+			}
+			s := widget.NewSelect(names, nil)
+			s.SetSelected(launch.Default(state.CurrentGame).Name)
+			dialog.ShowForm("Launch With", "Launch", "Cancel",
+				[]*widget.FormItem{widget.NewFormItem("Profile", s)},
+				func(ok bool) {
+					if ok && s.SelectedIndex() >= 0 {
+						launchGame(profiles[s.SelectedIndex()])
+					}
+				}, u.Window)
+		}),
+		fyne.NewMenuItem("Launch Profiles", showLaunchProfiles))
+}
+
+func launchGame(p *config.LaunchProfile) {
+	if err := launch.Launch(state.CurrentGame, p); err != nil {
+		util.ShowErrorLong(err)
+	}
+}
+
+func showLaunchProfiles() {
+	var (
+		lc       = config.Get().LaunchConfig(state.CurrentGame)
+		profiles = append([]*config.LaunchProfile{}, lc.Profiles...)
+		selected = -1
+		list     *widget.List
+		def      = widget.NewSelect(nil, nil)
+		verify   = widget.NewCheck("Verify the installed mods before launching", nil)
+	)
+	setDefaults := func(current string) {
+		names := []string{launch.SteamProfile}
+		for _, p := range state.CurrentGame.LaunchProfiles() {
+			names = append(names, p.Name)
+		}
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		def.Options = names
+		def.SetSelected(current)
+		if def.SelectedIndex() < 0 {
+			def.SetSelectedIndex(0)
+		}
+	}
+	setDefaults(launch.Default(state.CurrentGame).Name)
+	verify.SetChecked(lc.VerifyFirst)
+
+	list = widget.NewList(
+		func() int { return len(profiles) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(launchProfileText(profiles[id]))
+		})
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	d := dialog.NewCustomConfirm("Launch Profiles", "Save", "Cancel",
+		container.NewBorder(
+			container.NewVBox(
+				widget.NewForm(widget.NewFormItem("Launch With", def)),
+				verify),
+			container.NewHBox(
+				widget.NewButton("Add", func() {
+					addLaunchProfile(func(name string) bool {
+						for _, n := range def.Options {
+							if strings.EqualFold(n, name) {
+								return true
+							}
+						}
+						return false
+					}, func(p *config.LaunchProfile) {
+						profiles = append(profiles, p)
+						list.Refresh()
+						setDefaults(def.Selected)
+					})
+				}),
+				widget.NewButton("Remove", func() {
+					if selected >= 0 && selected < len(profiles) {
+						profiles = append(profiles[:selected], profiles[selected+1:]...)
+						list.UnselectAll()
+						list.Refresh()
+						setDefaults(def.Selected)
+					}
+				})),
+			nil, nil, list),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			lc.Profiles = profiles
+			lc.Default = def.Selected
+			if lc.Default == launch.SteamProfile {
+				lc.Default = ""
+			}
+			lc.VerifyFirst = verify.Checked
+			if err := config.Get().Save(); err != nil {
+				util.ShowErrorLong(err)
+			}
+		}, u.Window)
+	d.Resize(fyne.NewSize(600, 500))
+	d.Show()
+}
+
+func addLaunchProfile(exists func(name string) bool, added func(p *config.LaunchProfile)) {
+	var (
+		kinds   = make([]string, len(config.LaunchKinds))
+		name    = widget.NewEntry()
+		path    = widget.NewEntry()
+		command = widget.NewEntry()
+		args    = widget.NewMultiLineEntry()
+		env     = widget.NewMultiLineEntry()
+		dir     = widget.NewEntry()
+		kind    = widget.NewSelect(nil, func(s string) {
+			path.Disable()
+			command.Disable()
+			switch config.LaunchKind(s) {
+			case config.LaunchExecutable:
+				path.Enable()
+			case config.LaunchCommand:
+				command.Enable()
+			}
+		})
+	)
+	for i, k := range config.LaunchKinds {
+		kinds[i] = string(k)
+	}
+	kind.Options = kinds
+	kind.SetSelected(string(config.LaunchExecutable))
+	path.SetPlaceHolder(`e.g. FINAL FANTASY.exe, relative to the game's directory`)
+	command.SetPlaceHolder(`e.g. heroic launch ff1, or "$PROTON" run "$GAME_DIR/FINAL FANTASY.exe"`)
+	args.SetPlaceHolder("One argument per line")
+	env.SetPlaceHolder("One KEY=VALUE per line")
+
+	dialog.ShowForm("Add Launch Profile", "Add", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Name", name),
+			widget.NewFormItem("Kind", kind),
+			widget.NewFormItem("Executable", path),
+			widget.NewFormItem("Command", command),
+			widget.NewFormItem("Arguments", args),
+			widget.NewFormItem("Environment", env),
+			widget.NewFormItem("Working Dir", dir),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			p := &config.LaunchProfile{
+				Name:       strings.TrimSpace(name.Text),
+				Kind:       config.LaunchKind(kind.Selected),
+				WorkingDir: strings.TrimSpace(dir.Text),
+				Args:       splitLines(args.Text),
+			}
+			switch p.Kind {
+			case config.LaunchExecutable:
+				p.Path = strings.TrimSpace(path.Text)
+			case config.LaunchCommand:
+				p.Command = strings.TrimSpace(command.Text)
+			}
+			for _, l := range splitLines(env.Text) {
+				k, v, found := strings.Cut(l, "=")
+				if !found {
+					util.ShowErrorLong(fmt.Errorf("environment variable [%s] must be written as KEY=VALUE", l))
+					return
+				}
+				if p.Env == nil {
+					p.Env = make(map[string]string)
+				}
+				p.Env[strings.TrimSpace(k)] = v
+			}
+			if err := p.Validate(); err != nil {
+				util.ShowErrorLong(err)
+				return
+			}
+			if exists(p.Name) {
+				util.ShowErrorLong(fmt.Errorf("a launch profile named [%s] already exists", p.Name))
+				return
+			}
+			added(p)
+		}, u.Window)
+}
+
+func launchProfileText(p *config.LaunchProfile) string {
+	switch p.Kind {
+	case config.LaunchExecutable:
+		return fmt.Sprintf("%s - %s %s", p.Name, p.Path, strings.Join(p.Args, " "))
+	case config.LaunchCommand:
+		return fmt.Sprintf("%s - %s", p.Name, p.Command)
+	}
+	return fmt.Sprintf("%s - %s", p.Name, p.Kind)
+}
+
+func splitLines(s string) (lines []string) {
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package local
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/loadorder"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) showLoadOrder() {
+	var (
+		order    = loadorder.Get(state.CurrentGame)
+		ordered  = make(map[mods.ModID]bool)
+		selected = -1
+		list     *widget.List
+	)
+	for _, id := range order {
+		ordered[id] = true
+	}
+	for _, tm := range mods.SortTracked(managed.GetMods(state.CurrentGame)) {
+		if !ordered[tm.ID()] {
+			order = append(order, tm.ID())
+		}
+	}
+
+	move := func(by int) {
+		if to := selected + by; selected >= 0 && to >= 0 && to < len(order) {
+			order[selected], order[to] = order[to], order[selected]
+			list.Select(to)
+			list.Refresh()
+		}
+	}
+	list = widget.NewList(
+		func() int { return len(order) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, co fyne.CanvasObject) {
+			name := string(order[id])
+			if tm, found := managed.TryGetMod(state.CurrentGame, order[id]); found {
+				name = tm.DisplayName()
+			}
+			co.(*widget.Label).SetText(name)
+		})
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	d := dialog.NewCustomConfirm("Load Order", "Save", "Cancel",
+		container.NewBorder(
+			widget.NewLabel("Mods lower in the list load later and win file conflicts."),
+			container.NewHBox(
+				widget.NewButton("Up", func() { move(-1) }),
+				widget.NewButton("Down", func() { move(1) })),
+			nil, nil, list),
+		func(ok bool) {
+			if ok {
+				if err := loadorder.Set(state.CurrentGame, order); err != nil {
+					util.ShowErrorLong(err)
+				}
+			}
+		}, u.Window)
+	d.Resize(fyne.NewSize(400, 500))
+	d.Show()
+}
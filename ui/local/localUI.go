@@ -8,6 +8,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/config"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/mods/managed"
 	cw "github.com/kiamev/moogle-mod-manager/ui/custom-widgets"
@@ -17,12 +18,12 @@ import (
 	"github.com/kiamev/moogle-mod-manager/ui/util"
 	"github.com/kiamev/moogle-mod-manager/ui/util/working"
 	"github.com/ncruces/zenity"
-	"os/exec"
 )
 
 type LocalUI interface {
 	state.Screen
 	GetSelected() mods.TrackedMod
+	RestoreVanilla()
 }
 
 func New() LocalUI {
@@ -130,17 +131,23 @@ func (ui *localUI) Draw(w fyne.Window) {
 		})
 	})
 
-	launchGameButton := widget.NewButton("Launch Game", func() {
-		if err := exec.Command("explorer", fmt.Sprintf(`steam://rungameid/%s`, state.CurrentGame.SteamID())).Start(); err != nil {
-			util.ShowErrorLong(err)
-		}
-	})
+	profilesButton := ui.newProfilesButton()
+	loadOrderButton := widget.NewButton("Load Order", ui.showLoadOrder)
+	conflictRulesButton := widget.NewButton("Conflict Rules", ui.showConflictRules)
+	conflictsButton := widget.NewButton("Conflicts", ui.showConflicts)
+	verifyButton := widget.NewButton("Verify", ui.verify)
+	redeployButton := widget.NewButton("Redeploy", ui.redeploy)
+	snapshotsButton := ui.newSnapshotsButton()
+	dryRunButton := widget.NewButton("Dry Run", ui.dryRun)
+
+	launchGameButton := ui.newLaunchButton()
 
 	for _, mod := range managed.GetMods(state.CurrentGame) {
 		ui.addModToList(mod)
 	}
 
 	removeButton.Disable()
+	dryRunButton.Disable()
 	ui.ModList.OnSelected = func(id widget.ListItemID) {
 		data, err := ui.data.GetItem(id)
 		if err != nil {
@@ -149,6 +156,7 @@ func (ui *localUI) Draw(w fyne.Window) {
 		if i, ok := cw.GetValueFromDataItem(data); ok {
 			ui.selectedMod = i.(mods.TrackedMod)
 			removeButton.Enable()
+			dryRunButton.Enable()
 			ui.split.Trailing = container.NewCenter(widget.NewLabel(""))
 			ui.split.Refresh()
 			ui.split.Trailing = mp.CreatePreview(ui.selectedMod.Mod(), mp.ModPreviewOptions{
@@ -172,10 +180,11 @@ func (ui *localUI) Draw(w fyne.Window) {
 	ui.ModList.OnUnselected = func(id widget.ListItemID) {
 		ui.selectedMod = nil
 		removeButton.Disable()
+		dryRunButton.Disable()
 		ui.split.Trailing = container.NewMax()
 	}
 
-	buttons := container.NewHBox(findButton, addButton, removeButton, ui.checkAll, launchGameButton)
+	buttons := container.NewHBox(findButton, addButton, removeButton, dryRunButton, ui.checkAll, profilesButton, loadOrderButton, conflictRulesButton, conflictsButton, verifyButton, redeployButton, snapshotsButton, launchGameButton)
 	ui.split = container.NewHSplit(
 		ui.ModList,
 		container.NewMax())
@@ -183,13 +192,27 @@ func (ui *localUI) Draw(w fyne.Window) {
 
 	w.SetContent(container.NewBorder(
 		container.NewVBox(
-			widget.NewLabelWithStyle(string(state.CurrentGame.Name()), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.NewHBox(
+				widget.NewLabelWithStyle(string(state.CurrentGame.Name()), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				widget.NewLabel(gameVersionText())),
 			widget.NewSeparator(),
 			buttons,
 		), nil, nil, nil,
 		ui.split))
 }
 
+// gameVersionText describes the installed version of the game, empty when it was not installed by Steam.
+func gameVersionText() string {
+	v, m, err := config.InstalledVersion(state.CurrentGame)
+	if err != nil || m == nil {
+		return ""
+	}
+	if v == nil {
+		return fmt.Sprintf("Unknown version (build %d)", m.BuildID)
+	}
+	return fmt.Sprintf("Version %s (build %d)", v.Version, m.BuildID)
+}
+
 func (ui *localUI) addFromFile() {
 	var tm mods.TrackedMod
 	if file, err := zenity.SelectFile(
@@ -0,0 +1,66 @@
+package local
+
+import (
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/atotto/clipboard"
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+	"github.com/ncruces/zenity"
+)
+
+func (ui *localUI) dryRun() {
+	tm := ui.selectedMod
+	if tm == nil {
+		return
+	}
+	a, err := actions.New(actions.DryRun, state.CurrentGame, tm, func(r actions.Result) {
+		if r.Err != nil {
+			util.ShowErrorLong(r.Err)
+		} else if r.Status == mods.Ok && r.Plan != nil {
+			showPlan(r.Plan)
+		}
+	})
+	if err != nil {
+		util.ShowErrorLong(err)
+	} else if err = a.Run(); err != nil {
+		util.ShowErrorLong(err)
+	}
+}
+
+func showPlan(p *steps.Plan) {
+	b, err := p.JSON()
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	text := widget.NewRichTextWithText(p.String())
+	text.Wrapping = fyne.TextWrapBreak
+	content := container.NewBorder(
+		container.NewHBox(
+			widget.NewButton("Copy JSON To Clipboard", func() {
+				_ = clipboard.WriteAll(string(b))
+			}),
+			widget.NewButton("Save JSON", func() {
+				if file, err := zenity.SelectFileSave(
+					zenity.Title("Save the install plan"),
+					zenity.Filename(string(p.Mod)+"-plan.json"),
+					zenity.ConfirmOverwrite()); err == nil {
+					if err = os.WriteFile(file, b, 0644); err != nil {
+						util.ShowErrorLong(err)
+					}
+				}
+			})), nil, nil, nil,
+		container.NewVScroll(text))
+	d := dialog.NewCustom("Install Plan", "Close", content, u.Window)
+	d.Resize(fyne.NewSize(700, 500))
+	d.Show()
+}
@@ -0,0 +1,83 @@
+package local
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
+	cw "github.com/kiamev/moogle-mod-manager/ui/custom-widgets"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) newProfilesButton() *cw.ButtonWithPopups {
+	return cw.NewButtonWithPopups("Profiles",
+		fyne.NewMenuItem("Switch To", func() {
+			ui.selectProfile("Switch Profile", "Switch", ui.switchProfile)
+		}),
+		fyne.NewMenuItem("Save Current As", func() {
+			ui.saveProfile()
+		}),
+		fyne.NewMenuItem("Delete", func() {
+			ui.selectProfile("Delete Profile", "Delete", func(name string) {
+				if err := profiles.Delete(state.CurrentGame, name); err != nil {
+					util.ShowErrorLong(err)
+				}
+			})
+		}))
+}
+
+func (ui *localUI) selectProfile(title string, confirm string, onSelected func(name string)) {
+	names := profiles.Names(state.CurrentGame)
+	if len(names) == 0 {
+		dialog.ShowInformation(title, "No profiles have been saved for this game.", u.Window)
+		return
+	}
+	s := widget.NewSelect(names, nil)
+	s.SetSelected(profiles.Active(state.CurrentGame))
+	dialog.ShowForm(title, confirm, "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Profile", s)},
+		func(ok bool) {
+			if ok && s.Selected != "" {
+				onSelected(s.Selected)
+			}
+		}, u.Window)
+}
+
+func (ui *localUI) saveProfile() {
+	e := widget.NewEntry()
+	e.SetText(profiles.Active(state.CurrentGame))
+	dialog.ShowForm("Save Profile", "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", e)},
+		func(ok bool) {
+			if ok {
+				if _, err := profiles.Save(state.CurrentGame, e.Text); err != nil {
+					util.ShowErrorLong(err)
+				}
+			}
+		}, u.Window)
+}
+
+func (ui *localUI) switchProfile(name string) {
+	a, err := actions.NewProfileSwitch(state.CurrentGame, name, func(r actions.Result) {
+		for _, tm := range r.RequiredMods {
+			ui.addModToList(tm)
+		}
+		ui.ModList.Refresh()
+		if r.Err != nil {
+			util.ShowErrorLong(r.Err)
+		} else if r.Status == mods.Ok {
+			dialog.ShowInformation("Profiles", fmt.Sprintf("Switched to %s.", name), u.Window)
+		}
+	})
+	if err != nil {
+		util.ShowErrorLong(err)
+	} else if err = a.Run(); err != nil {
+		util.ShowErrorLong(err)
+	}
+}
@@ -0,0 +1,62 @@
+package local
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/actions/steps"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) redeploy() {
+	changed, err := steps.ChangedGameFiles(state.CurrentGame)
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("Every enabled mod will be uninstalled and installed again with the same choices.\n")
+	if len(changed) == 0 {
+		sb.WriteString("\nNo installed file was changed by the game.\n")
+	} else {
+		sb.WriteString("\nThese files were changed, most likely by a game update, and will become the backups of the game's files:\n")
+		for _, i := range changed {
+			name := string(i.Mod)
+			if tm, found := managed.TryGetMod(state.CurrentGame, i.Mod); found {
+				name = tm.DisplayName()
+			}
+			sb.WriteString(fmt.Sprintf("%s - %s\n", name, i))
+		}
+	}
+	text := widget.NewRichTextWithText(sb.String())
+	text.Wrapping = fyne.TextWrapBreak
+	d := dialog.NewCustomConfirm("Redeploy", "Redeploy", "Cancel", container.NewVScroll(text), func(ok bool) {
+		if !ok {
+			return
+		}
+		a, err := actions.NewRedeploy(state.CurrentGame, changed, func(r actions.Result) {
+			ui.ModList.Refresh()
+			if r.Err != nil {
+				util.ShowErrorLong(r.Err)
+			} else if r.Status == mods.Ok {
+				dialog.ShowInformation("Redeploy", "The enabled mods were installed again.", u.Window)
+			}
+		})
+		if err != nil {
+			util.ShowErrorLong(err)
+		} else if err = a.Run(); err != nil {
+			util.ShowErrorLong(err)
+		}
+	}, u.Window)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
@@ -0,0 +1,62 @@
+package local
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/atotto/clipboard"
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) RestoreVanilla() {
+	dialog.ShowConfirm("Restore Vanilla",
+		"This will uninstall every enabled mod and restore all of the game's backed up files. Are you sure you want to continue?",
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			game := state.CurrentGame
+			a, err := actions.NewRestoreVanilla(game, func(r actions.Result) {
+				ui.ModList.Refresh()
+				if r.Err != nil {
+					util.ShowErrorLong(r.Err)
+				}
+				l, err := files.CheckVanilla(game)
+				if err != nil {
+					util.ShowErrorLong(err)
+					return
+				}
+				showLeftovers(l)
+			})
+			if err != nil {
+				util.ShowErrorLong(err)
+			} else if err = a.Run(); err != nil {
+				util.ShowErrorLong(err)
+			}
+		}, u.Window)
+}
+
+func showLeftovers(l *files.Leftovers) {
+	if l.Clean() {
+		dialog.ShowInformation("Restore Vanilla", l.String(), u.Window)
+		return
+	}
+	text := widget.NewRichTextWithText(l.String())
+	text.Wrapping = fyne.TextWrapBreak
+	d := dialog.NewCustom("Restore Vanilla", "Close",
+		container.NewBorder(
+			container.NewVBox(
+				widget.NewLabel("The game could not be fully restored, these were left over:"),
+				widget.NewButton("Copy To Clipboard", func() {
+					_ = clipboard.WriteAll(l.String())
+				})), nil, nil, nil,
+			container.NewVScroll(text)),
+		u.Window)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
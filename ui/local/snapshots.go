@@ -0,0 +1,141 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/snapshots"
+	cw "github.com/kiamev/moogle-mod-manager/ui/custom-widgets"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) newSnapshotsButton() *cw.ButtonWithPopups {
+	return cw.NewButtonWithPopups("Snapshots",
+		fyne.NewMenuItem("Rollback", ui.rollback),
+		fyne.NewMenuItem("Take Snapshot", takeSnapshot),
+		fyne.NewMenuItem("Manage Snapshots", ui.showSnapshots))
+}
+
+func (ui *localUI) rollback() {
+	s, err := snapshots.Latest(state.CurrentGame)
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	if s == nil {
+		util.ShowErrorLong(errors.New("there are no snapshots to roll back to"))
+		return
+	}
+	ui.restoreSnapshot(s)
+}
+
+func takeSnapshot() {
+	name := widget.NewEntry()
+	name.SetPlaceHolder("e.g. Before trying the new battle mods")
+	dialog.ShowForm("Take Snapshot", "Take", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", name)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if actions.Running() {
+				util.ShowErrorLong(errors.New("a snapshot cannot be taken while a mod is being installed or uninstalled"))
+				return
+			}
+			n := strings.TrimSpace(name.Text)
+			if n == "" {
+				n = "Snapshot"
+			}
+			if s, err := snapshots.Take(state.CurrentGame, n, false); err != nil {
+				util.ShowErrorLong(fmt.Errorf("failed to take a snapshot: %v", err))
+			} else {
+				dialog.ShowInformation("Take Snapshot", fmt.Sprintf("Took snapshot %s.", s.ID), u.Window)
+			}
+		}, u.Window)
+}
+
+func (ui *localUI) showSnapshots() {
+	l, damaged, err := snapshots.List(state.CurrentGame)
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	if len(damaged) > 0 {
+		sl := make([]string, len(damaged))
+		for i, e := range damaged {
+			sl[i] = e.Error()
+		}
+		util.ShowErrorLong(fmt.Errorf("these snapshots cannot be read and were left out:\n%s", strings.Join(sl, "\n")))
+	}
+	var (
+		selected = -1
+		list     = widget.NewList(
+			func() int { return len(l) },
+			func() fyne.CanvasObject { return widget.NewLabel("") },
+			func(id widget.ListItemID, co fyne.CanvasObject) {
+				co.(*widget.Label).SetText(l[id].String())
+			})
+		d dialog.Dialog
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	d = dialog.NewCustom("Snapshots", "Close",
+		container.NewBorder(
+			widget.NewLabel("Restoring a snapshot puts the game's modded files and mods back the way they were."),
+			container.NewHBox(
+				widget.NewButton("Restore", func() {
+					if selected >= 0 && selected < len(l) {
+						d.Hide()
+						ui.restoreSnapshot(l[selected])
+					}
+				}),
+				widget.NewButton("Delete", func() {
+					if selected < 0 || selected >= len(l) {
+						return
+					}
+					if err := snapshots.Delete(state.CurrentGame, l[selected].ID); err != nil {
+						util.ShowErrorLong(err)
+						return
+					}
+					l = append(l[:selected], l[selected+1:]...)
+					list.UnselectAll()
+					list.Refresh()
+				})),
+			nil, nil, list),
+		u.Window)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+func (ui *localUI) restoreSnapshot(s *snapshots.Snapshot) {
+	dialog.ShowConfirm("Restore Snapshot",
+		fmt.Sprintf("Every enabled mod will be uninstalled and the game put back the way it was at snapshot\n%s\n\nAre you sure you want to continue?", s),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			a, err := actions.NewRestoreSnapshot(state.CurrentGame, s, func(r actions.Result) {
+				ui.ModList.Refresh()
+				if r.Err != nil {
+					util.ShowErrorLong(r.Err)
+				} else if r.Status == mods.Ok {
+					dialog.ShowInformation("Restore Snapshot", fmt.Sprintf("Restored snapshot %s.", s.ID), u.Window)
+				}
+			})
+			if err != nil {
+				util.ShowErrorLong(err)
+			} else if err = a.Run(); err != nil {
+				util.ShowErrorLong(err)
+			}
+		}, u.Window)
+}
@@ -0,0 +1,87 @@
+package local
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/atotto/clipboard"
+	"github.com/kiamev/moogle-mod-manager/actions"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	u "github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+)
+
+func (ui *localUI) verify() {
+	issues, err := files.VerifyAll(state.CurrentGame)
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	if len(issues) == 0 {
+		dialog.ShowInformation("Verify", "All installed files and backups match what was installed.", u.Window)
+		return
+	}
+
+	var (
+		sb         strings.Builder
+		repairable bool
+		backups    bool
+	)
+	for _, i := range issues {
+		name := string(i.Mod)
+		if tm, found := managed.TryGetMod(state.CurrentGame, i.Mod); found {
+			name = tm.DisplayName()
+		}
+		sb.WriteString(fmt.Sprintf("%s - %s\n", name, i))
+		if i.CanRepair() {
+			repairable = true
+		} else {
+			backups = true
+		}
+	}
+	if backups {
+		sb.WriteString("\nBackups can only be restored by verifying the game's files with Steam.\n")
+	}
+	text := widget.NewRichTextWithText(sb.String())
+	text.Wrapping = fyne.TextWrapBreak
+	content := container.NewBorder(
+		widget.NewButton("Copy To Clipboard", func() {
+			_ = clipboard.WriteAll(text.String())
+		}), nil, nil, nil,
+		container.NewVScroll(text))
+
+	var d dialog.Dialog
+	if repairable {
+		d = dialog.NewCustomConfirm("Verify", "Repair", "Close", content, func(ok bool) {
+			if ok {
+				ui.repair(issues)
+			}
+		}, u.Window)
+	} else {
+		d = dialog.NewCustom("Verify", "Close", content, u.Window)
+	}
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+func (ui *localUI) repair(issues []*files.Issue) {
+	a, err := actions.NewRepair(state.CurrentGame, issues, func(r actions.Result) {
+		if r.Err != nil {
+			util.ShowErrorLong(r.Err)
+		} else if r.Status == mods.Ok {
+			ui.verify()
+		}
+	})
+	if err != nil {
+		util.ShowErrorLong(err)
+	} else if err = a.Run(); err != nil {
+		util.ShowErrorLong(err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/kiamev/moogle-mod-manager/files"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/mods/managed"
+	"github.com/kiamev/moogle-mod-manager/mods/managed/profiles"
 	"github.com/kiamev/moogle-mod-manager/ui/configure"
 	"github.com/kiamev/moogle-mod-manager/ui/local"
 	a "github.com/kiamev/moogle-mod-manager/ui/mod-author"
@@ -102,6 +103,9 @@ func (m *MainMenu) createFileMenu() {
 	if state.GetCurrentGUI() == state.LocalMods {
 		m.file.Items = append(m.file.Items,
 			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Restore Vanilla", func() {
+				state.GetScreen(state.LocalMods).(local.LocalUI).RestoreVanilla()
+			}),
 			fyne.NewMenuItem("Force Disable All Mods (Debug)", func() {
 				dialog.ShowConfirm(
 					"Force Disable All Mods",
@@ -113,6 +117,9 @@ func (m *MainMenu) createFileMenu() {
 
 							managed.ForceDisableAll(game)
 							files.RemoveAllFilesForGame(game)
+							if err := profiles.RemoveWinners(game); err != nil {
+								util.ShowErrorLong(err)
+							}
 
 							state.CurrentGame = game
 							state.ShowScreen(state.LocalMods)
@@ -134,6 +141,9 @@ func (m *MainMenu) createFileMenu() {
 
 							managed.ForceDisable(tm)
 							files.RemoveAllFilesForMod(game, tm.ID())
+							if err := profiles.RemoveWinners(game, tm.ID()); err != nil {
+								util.ShowErrorLong(err)
+							}
 
 							state.CurrentGame = game
 							state.ShowScreen(state.LocalMods)
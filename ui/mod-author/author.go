@@ -285,9 +285,14 @@ func (a *ModAuthorer) Draw(w fyne.Window) {
 			if len(a.configsDef.list.Items) == 0 {
 				util.DisplayDownloadsAndFiles(tis)
 			} else {
-				if err = state.GetScreen(state.ConfigInstaller).(config_installer.ConfigInstaller).Setup(mod, state.GetBaseDir(), func(r mods.Result, tis []*mods.ToInstall) error {
-					if r == mods.Ok && len(tis) > 0 {
-						util.DisplayDownloadsAndFiles(tis)
+				if err = state.GetScreen(state.ConfigInstaller).(config_installer.ConfigInstaller).Setup(mod, state.GetBaseDir(), func(r mods.Result, choices []*mods.Choice) error {
+					if r == mods.Ok {
+						if tis, err = mods.NewToInstallForMod(mod, mod.DownloadFilesForChoices(choices)); err != nil {
+							return err
+						}
+						if len(tis) > 0 {
+							util.DisplayDownloadsAndFiles(tis)
+						}
 					}
 					return nil
 				}); err != nil {
@@ -297,6 +302,7 @@ func (a *ModAuthorer) Draw(w fyne.Window) {
 				state.ShowScreen(state.ConfigInstaller)
 			}
 		}),
+		widget.NewButton("Pack", a.pack),
 		widget.NewSeparator(),
 		cw.NewButtonWithPopups("Manual Edit",
 			fyne.NewMenuItem("copy as json", func() {
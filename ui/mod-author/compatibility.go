@@ -7,21 +7,26 @@ import (
 )
 
 type modCompatabilityDef struct {
-	requires *modCompatsDef
-	forbids  *modCompatsDef
+	requires         *modCompatsDef
+	forbids          *modCompatsDef
+	orderConstraints *modCompatsDef
 }
 
 func newModCompatibilityDef(gamesDef *gamesDef) *modCompatabilityDef {
-	return &modCompatabilityDef{
-		requires: newModCompatsDef("Requires", gamesDef),
-		forbids:  newModCompatsDef("Forbids", gamesDef),
+	d := &modCompatabilityDef{
+		requires:         newModCompatsDef("Requires", gamesDef),
+		forbids:          newModCompatsDef("Forbids", gamesDef),
+		orderConstraints: newModCompatsDef("Load Order", gamesDef),
 	}
+	d.orderConstraints.withOrder = true
+	return d
 }
 
 func (d *modCompatabilityDef) draw() fyne.CanvasObject {
 	return container.NewVScroll(container.NewVBox(
 		d.requires.draw(),
 		d.forbids.draw(),
+		d.orderConstraints.draw(),
 	))
 }
 
@@ -30,14 +35,16 @@ func (d *modCompatabilityDef) compile() *mods.ModCompatibility {
 		return nil
 	}
 	return &mods.ModCompatibility{
-		Requires: d.requires.compile(),
-		Forbids:  d.forbids.compile(),
+		Requires:         d.requires.compile(),
+		Forbids:          d.forbids.compile(),
+		OrderConstraints: d.orderConstraints.compile(),
 	}
 }
 
 func (d *modCompatabilityDef) set(compatibility *mods.ModCompatibility) {
 	d.requires.clear()
 	d.forbids.clear()
+	d.orderConstraints.clear()
 	if compatibility != nil {
 		for _, i := range compatibility.Requires {
 			d.requires.list.AddItem(i)
@@ -45,5 +52,8 @@ func (d *modCompatabilityDef) set(compatibility *mods.ModCompatibility) {
 		for _, i := range compatibility.Forbids {
 			d.forbids.list.AddItem(i)
 		}
+		for _, i := range compatibility.OrderConstraints {
+			d.orderConstraints.list.AddItem(i)
+		}
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/kiamev/moogle-mod-manager/config"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/ui/mod-author/entry"
+	"strings"
 )
 
 type downloadFilesDef struct {
@@ -24,17 +25,33 @@ func newDownloadFilesDef(downloads *downloads, installType *config.InstallType,
 	}
 	var i any = entry.NewSelectEntry(d, "Download Name", "", nil)
 	d.selectEntry = i.(*entry.SelectFormEntry)
+	entry.NewEntry[string](d, entry.KindMultiLine, "Include", "")
+	entry.NewEntry[string](d, entry.KindMultiLine, "Exclude", "")
+	entry.NewEntry[bool](d, entry.KindBool, "Replace Default Excludes", false)
 	return d
 }
 
 func (d *downloadFilesDef) compile() *mods.DownloadFiles {
 	return &mods.DownloadFiles{
-		DownloadName: entry.Value[string](d, "Download Name"),
-		Files:        d.files.compile(),
-		Dirs:         d.dirs.compile(),
+		DownloadName:           entry.Value[string](d, "Download Name"),
+		Files:                  d.files.compile(),
+		Dirs:                   d.dirs.compile(),
+		Include:                splitPatterns(entry.Value[string](d, "Include")),
+		Exclude:                splitPatterns(entry.Value[string](d, "Exclude")),
+		ReplaceDefaultExcludes: entry.Value[bool](d, "Replace Default Excludes"),
 	}
 }
 
+// splitPatterns returns the glob patterns written one per line.
+func splitPatterns(s string) (patterns []string) {
+	for _, p := range strings.Split(s, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return
+}
+
 /*func (d *downloadFilesDef) draw() fyne.CanvasObject {
 	var possible []string
 	for _, dl := range d.downloads.compileDownloads() {
@@ -67,6 +84,9 @@ func (d *downloadFilesDef) getFormItems() ([]*widget.FormItem, error) {
 		entry.FormItem[string](d, "Download Name"),
 		widget.NewFormItem("Files", d.files.draw(false)),
 		widget.NewFormItem("Dirs", d.dirs.draw(false)),
+		entry.FormItem[string](d, "Include"),
+		entry.FormItem[string](d, "Exclude"),
+		entry.FormItem[bool](d, "Replace Default Excludes"),
 	}, nil
 }
 
@@ -74,6 +94,9 @@ func (d *downloadFilesDef) clear() {
 	d.selectEntry.Set("")
 	d.files.clear()
 	d.dirs.clear()
+	entry.NewEntry[string](d, entry.KindMultiLine, "Include", "")
+	entry.NewEntry[string](d, entry.KindMultiLine, "Exclude", "")
+	entry.NewEntry[bool](d, entry.KindBool, "Replace Default Excludes", false)
 }
 
 func (d *downloadFilesDef) populate(dlf *mods.DownloadFiles) {
@@ -83,6 +106,9 @@ func (d *downloadFilesDef) populate(dlf *mods.DownloadFiles) {
 		d.selectEntry.Set(dlf.DownloadName)
 		d.files.populate(dlf.Files)
 		d.dirs.populate(dlf.Dirs)
+		entry.NewEntry[string](d, entry.KindMultiLine, "Include", strings.Join(dlf.Include, "\n"))
+		entry.NewEntry[string](d, entry.KindMultiLine, "Exclude", strings.Join(dlf.Exclude, "\n"))
+		entry.NewEntry[bool](d, entry.KindBool, "Replace Default Excludes", dlf.ReplaceDefaultExcludes)
 	}
 }
 
@@ -6,11 +6,17 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/browser"
 	"github.com/kiamev/moogle-mod-manager/mods"
 	cw "github.com/kiamev/moogle-mod-manager/ui/custom-widgets"
 	"github.com/kiamev/moogle-mod-manager/ui/mod-author/entry"
 	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+	"github.com/kiamev/moogle-mod-manager/ui/util/working"
+	u "github.com/kiamev/moogle-mod-manager/util"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -46,9 +52,7 @@ func (d *downloadsDef) compileDownloads() ([]*mods.Download, error) {
 		di := item.(*mods.Download)
 		if di.Hosted != nil && len(di.Hosted.Sources) > 0 {
 			di.Name = filepath.Base(di.Hosted.Sources[0])
-			if j := strings.LastIndex(di.Name, "."); j != -1 {
-				di.Name = di.Name[:j]
-			}
+			di.Name = u.TrimArchiveExt(di.Name)
 		}
 		dls[i] = di
 	}
@@ -84,13 +88,26 @@ func (d *downloadsDef) createItem(item interface{}, done ...func(interface{})) {
 	if m.Hosted != nil {
 		sources = m.Hosted.Sources
 	}
+	var (
+		size       string
+		oldSources = strings.Join(sources, "\n")
+		oldSha256  = m.Sha256
+	)
+	if m.Size != 0 {
+		size = strconv.FormatInt(m.Size, 10)
+	}
 	entry.NewEntry[string](d, entry.KindMultiLine, "Sources", strings.Join(sources, "\n"))
 	entry.NewEntry[string](d, entry.KindString, "Version", m.Version)
+	entry.NewEntry[string](d, entry.KindString, "Sha256", m.Sha256)
+	entry.NewEntry[string](d, entry.KindString, "Size", size)
 
 	items = []*widget.FormItem{
 		entry.FormItem[string](d, "Version"),
 		entry.FormItem[string](d, "Sources"),
+		entry.FormItem[string](d, "Sha256"),
+		entry.FormItem[string](d, "Size"),
 	}
+	items[2].HintText = "Computed from the first source when left empty"
 
 	fd := dialog.NewForm("Edit Downloadable", "Save", "Cancel", items, func(ok bool) {
 		if ok {
@@ -103,19 +120,77 @@ func (d *downloadsDef) createItem(item interface{}, done ...func(interface{})) {
 				m.Name = filepath.Base(m.Hosted.Sources[0])
 			}
 			if m.Name != "" {
-				m.Name = strings.TrimSuffix(m.Name, filepath.Ext(m.Name))
+				m.Name = u.TrimArchiveExt(m.Name)
+			}
+			var (
+				s       = strings.TrimSpace(entry.Value[string](d, "Size"))
+				changed = entry.Value[string](d, "Sources") != oldSources
+			)
+			m.Sha256 = strings.TrimSpace(entry.Value[string](d, "Sha256"))
+			if m.Sha256 == oldSha256 && changed {
+				// The checksum and size belong to the old file
+				m.Sha256 = ""
+				if s == size {
+					s = ""
+				}
+			}
+			m.Size = 0
+			if s != "" {
+				var err error
+				if m.Size, err = strconv.ParseInt(s, 10, 64); err != nil {
+					util.ShowErrorLong(fmt.Errorf("invalid size %s", s))
+				}
 			}
 			//m.InstallType = mods.InstallType(entry.Value[string](d, "Install Type"))
-			for _, dn := range done {
-				dn(m)
+			finish := func() {
+				for _, dn := range done {
+					dn(m)
+				}
+				d.list.Refresh()
 			}
-			d.list.Refresh()
+			if m.Sha256 == "" && len(m.Hosted.Sources) > 0 && m.Hosted.Sources[0] != "" {
+				go func() {
+					working.ShowDialog()
+					hash, size, err := computeIntegrity(m.Hosted.Sources[0])
+					working.HideDialog()
+					if err != nil {
+						util.ShowErrorLong(fmt.Errorf("failed to compute the checksum of %s: %v", m.Hosted.Sources[0], err))
+					} else {
+						m.Sha256, m.Size = hash, size
+					}
+					finish()
+				}()
+				return
+			}
+			finish()
 		}
 	}, ui.Window)
 	fd.Resize(fyne.NewSize(600, 400))
 	fd.Show()
 }
 
+// computeIntegrity downloads url to a temporary directory and returns its SHA-256 and size.
+func computeIntegrity(url string) (hash string, size int64, err error) {
+	var (
+		dir, f string
+		fi     os.FileInfo
+	)
+	if dir, err = os.MkdirTemp("", "mmm"); err != nil {
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if f, err = browser.Download(url, dir); err != nil {
+		return
+	}
+	if fi, err = os.Stat(f); err != nil {
+		return
+	}
+	if hash, err = u.HashFile(f); err != nil {
+		return
+	}
+	return hash, fi.Size(), nil
+}
+
 func (d *downloadsDef) draw() *container.TabItem {
 	return container.NewTabItem("Direct Download",
 		container.NewVScroll(container.NewVBox(container.NewHBox(
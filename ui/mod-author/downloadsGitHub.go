@@ -8,7 +8,7 @@ import (
 	"github.com/kiamev/moogle-mod-manager/mods"
 	"github.com/kiamev/moogle-mod-manager/ui/mod-author/entry"
 	"github.com/kiamev/moogle-mod-manager/ui/util"
-	"strings"
+	u "github.com/kiamev/moogle-mod-manager/util"
 )
 
 type githubDownloadsDef struct {
@@ -70,9 +70,7 @@ func (d *githubDownloadsDef) compileDownloads() (result []*mods.Download, err er
 	result = make([]*mods.Download, len(dls))
 	for i, dl := range dls {
 		name := dl.Name
-		if j := strings.LastIndex(name, "."); j != -1 {
-			name = name[:j]
-		}
+		name = u.TrimArchiveExt(name)
 		result[i] = &mods.Download{
 			Name:    name,
 			Version: version,
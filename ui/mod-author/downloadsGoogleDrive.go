@@ -10,7 +10,7 @@ import (
 	cw "github.com/kiamev/moogle-mod-manager/ui/custom-widgets"
 	"github.com/kiamev/moogle-mod-manager/ui/mod-author/entry"
 	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
-	"path/filepath"
+	u "github.com/kiamev/moogle-mod-manager/util"
 	"strings"
 )
 
@@ -46,9 +46,7 @@ func (d *googleDriveDownloadsDef) compileDownloads() ([]*mods.Download, error) {
 		di := item.(*mods.Download)
 		if di.GoogleDrive != nil {
 			di.Name = di.GoogleDrive.Name
-			if j := strings.LastIndex(di.Name, "."); j != -1 {
-				di.Name = di.Name[:j]
-			}
+			di.Name = u.TrimArchiveExt(di.Name)
 		}
 		dls[i] = di
 	}
@@ -100,7 +98,7 @@ func (d *googleDriveDownloadsDef) createItem(item interface{}, done ...func(inte
 			fileName = strings.TrimSpace(entry.Value[string](d, "File Name"))
 			m.GoogleDrive.Name = fileName
 			m.GoogleDrive.Url = entry.Value[string](d, "URL")
-			m.Name = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+			m.Name = u.TrimArchiveExt(fileName)
 			for _, dn := range done {
 				dn(m)
 			}
@@ -23,6 +23,8 @@ type modCompatsDef struct {
 	list *cw.DynamicList
 	name string
 	gd   *gamesDef
+	// withOrder asks for the Before/After order of each mod
+	withOrder bool
 }
 
 func newModCompatsDef(name string, gd *gamesDef) *modCompatsDef {
@@ -56,6 +58,9 @@ func (d *modCompatsDef) getItemKey(item interface{}) string {
 }
 
 func (d *modCompatsDef) getItemFields(item interface{}) []string {
+	if d.withOrder {
+		return []string{string(item.(*mods.ModCompat).Order)}
+	}
 	return nil
 }
 
@@ -101,10 +106,18 @@ func (d *modCompatsDef) createItem(item interface{}, done ...func(interface{}))
 		search.ShowCompletion()
 	}
 
-	fd := dialog.NewForm("Edit Mod Compatibility", "Save", "Cancel", []*widget.FormItem{
-		widget.NewFormItem("Mod", search),
-	}, func(ok bool) {
+	items := []*widget.FormItem{widget.NewFormItem("Mod", search)}
+	order := widget.NewSelect(mods.ModCompatOrders[1:], nil)
+	if d.withOrder {
+		order.SetSelected(string(m.Order))
+		items = append(items, widget.NewFormItem("Load", order))
+	}
+
+	fd := dialog.NewForm("Edit Mod Compatibility", "Save", "Cancel", items, func(ok bool) {
 		if ok {
+			if d.withOrder {
+				m.Order = mods.ModCompatOrder(order.Selected)
+			}
 			var selected *mods.Mod
 			m.ID = ""
 			if search.Text != "" {
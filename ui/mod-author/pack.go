@@ -0,0 +1,106 @@
+package mod_author
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/pack"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/ui/util"
+	u "github.com/kiamev/moogle-mod-manager/util"
+	"github.com/ncruces/zenity"
+)
+
+// pack zips a folder laid out like the game's directory and fills in the mod's download and file mappings for it.
+func (a *ModAuthorer) pack() {
+	mod, err := a.compileMod()
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	game := packGame(mod)
+	if game == nil {
+		util.ShowErrorLong(fmt.Errorf("add a game to %s before packing it", mod.Name))
+		return
+	}
+	dir, err := zenity.SelectFile(
+		zenity.Title(fmt.Sprintf("Select the folder to pack, laid out like the directory of %s", game.Name())),
+		zenity.Filename(state.GetBaseDir()),
+		zenity.Directory())
+	if err != nil {
+		return
+	}
+	name := string(mod.ModID)
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	archive, err := zenity.SelectFileSave(
+		zenity.Title("Save the mod's archive"),
+		zenity.Filename(filepath.Join(filepath.Dir(dir), name+".zip")),
+		zenity.ConfirmOverwrite(),
+		zenity.FileFilter{
+			Name:     "*.zip",
+			Patterns: []string{"*.zip"},
+		})
+	if err != nil {
+		return
+	}
+	if filepath.Ext(archive) == "" {
+		archive += ".zip"
+	}
+
+	r, err := pack.Pack(game, mod, dir, archive)
+	if err != nil {
+		util.ShowErrorLong(err)
+		return
+	}
+	a.updateEntries(mod)
+	showPacked(r)
+}
+
+// packGame returns the game being modded when the mod supports it, otherwise the first game the mod lists.
+func packGame(mod *mods.Mod) config.GameDef {
+	if state.CurrentGame != nil && mod.Supports(state.CurrentGame) == nil {
+		return state.CurrentGame
+	}
+	for _, g := range mod.Games {
+		if game, err := config.GameDefFromID(g.ID); err == nil {
+			return game
+		}
+	}
+	return nil
+}
+
+func showPacked(r *pack.Result) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Packed %d files into %s\n\nSize: %s\nSHA-256: %s\n\nDownload [%s] now installs:\n",
+		len(r.Files), r.Archive, u.FormatSize(r.Size), r.Sha256, r.Download.Name))
+	for _, d := range r.Mappings.Dirs {
+		sb.WriteString(fmt.Sprintf("  %s -> %s\n", d.From, pack.Target(d.To, d.ToArchive)))
+	}
+	for _, f := range r.Mappings.Files {
+		sb.WriteString(fmt.Sprintf("  %s -> %s\n", f.From, pack.Target(f.To, f.ToArchive)))
+	}
+	if len(r.Included) > 0 {
+		sb.WriteString("\nIncluded the files left out by default:\n")
+		for _, f := range r.Included {
+			sb.WriteString(fmt.Sprintf("  %s\n", f))
+		}
+	}
+	if r.Download.Hosted != nil && len(r.Download.Hosted.Sources) == 0 {
+		sb.WriteString("\nAdd where the archive will be uploaded to the download's sources.\n")
+	}
+	text := widget.NewRichTextWithText(sb.String())
+	text.Wrapping = fyne.TextWrapBreak
+	d := dialog.NewCustom("Pack", "Close", container.NewVScroll(text), ui.Window)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
@@ -1,6 +1,7 @@
 package mod_preview
 
 import (
+	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
@@ -124,6 +125,18 @@ func createCompatibility(game config.GameDef, compatibility *mods.ModCompatibili
 			c.Add(widget.NewLabel("  - " + name))
 		}
 	}
+
+	// Load Order
+	if len(compatibility.OrderConstraints) > 0 {
+		c.Add(widget.NewLabelWithStyle("  Load Order", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, r := range compatibility.OrderConstraints {
+			name, err = discover.GetDisplayName(game, r.ModID())
+			if err != nil {
+				util.ShowErrorLong(err)
+			}
+			c.Add(widget.NewLabel(fmt.Sprintf("  - %s %s", r.Order, name)))
+		}
+	}
 	return c
 }
 
@@ -0,0 +1,126 @@
+package prompt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/kiamev/moogle-mod-manager/config"
+	"github.com/kiamev/moogle-mod-manager/files"
+	"github.com/kiamev/moogle-mod-manager/mods"
+	"github.com/kiamev/moogle-mod-manager/prompt"
+	ci "github.com/kiamev/moogle-mod-manager/ui/config-installer"
+	"github.com/kiamev/moogle-mod-manager/ui/confirm"
+	uic "github.com/kiamev/moogle-mod-manager/ui/conflicts"
+	"github.com/kiamev/moogle-mod-manager/ui/state"
+	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
+)
+
+type guiPrompter struct{}
+
+// New creates a Prompter that asks its questions using the application's screens and dialogs.
+func New() prompt.Prompter {
+	return &guiPrompter{}
+}
+
+func (p *guiPrompter) Configure(_ config.GameDef, mod *mods.Mod, modPath string) (result mods.Result, choices []*mods.Choice, err error) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	if err = state.GetScreen(state.ConfigInstaller).(ci.ConfigInstaller).Setup(mod, modPath, func(r mods.Result, c []*mods.Choice) error {
+		result = r
+		choices = c
+		wg.Done()
+		return nil
+	}); err != nil {
+		// Failed to set up config installer screen
+		return mods.Error, nil, err
+	}
+	state.ShowScreen(state.ConfigInstaller)
+	wg.Wait()
+	time.Sleep(100 * time.Millisecond)
+	return
+}
+
+func (p *guiPrompter) ConfirmDownloads(game config.GameDef, tm mods.TrackedMod, toInstall []*mods.ToInstall) (result mods.Result, err error) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	confirmer := confirm.NewConfirmer(confirm.NewParams(game, tm, toInstall))
+	if err = confirmer.Downloads(func(r mods.Result) {
+		result = r
+		wg.Done()
+	}); err != nil {
+		return mods.Error, err
+	}
+	wg.Wait()
+	time.Sleep(100 * time.Millisecond)
+	return
+}
+
+func (p *guiPrompter) ResolveConflicts(mod *mods.Mod, conflicts []*files.Conflict) (result mods.Result, remember bool) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	uic.ShowConflicts(mod, conflicts, func(r mods.Result, rem bool) {
+		result = r
+		remember = rem
+		wg.Done()
+	})
+	wg.Wait()
+	return
+}
+
+func (p *guiPrompter) EnableRequiredMod(baseModName mods.ModName, neededMod *mods.Mod) (result mods.Result) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	confirm.ShowEnableModConfirmDialog(baseModName, neededMod, func(r mods.Result) {
+		result = r
+		wg.Done()
+	})
+	wg.Wait()
+	return
+}
+
+func (p *guiPrompter) UpdateRequiredMod(baseModName mods.ModName, required mods.TrackedMod, update *mods.Mod) (result mods.Result) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	confirm.ShowUpdateModConfirmDialog(baseModName, required, update, func(r mods.Result) {
+		result = r
+		wg.Done()
+	})
+	wg.Wait()
+	return
+}
+
+func (p *guiPrompter) AllowGameVersion(mod *mods.Mod, installed config.VersionID) (result mods.Result) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	confirm.ShowGameVersionConfirmDialog(mod, installed, func(r mods.Result) {
+		result = r
+		wg.Done()
+	})
+	wg.Wait()
+	return
+}
+
+func (p *guiPrompter) Missing7zip(url string) (mods.Result, error) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	d := dialog.NewCustom(
+		"7-Zip not found",
+		"Ok",
+		container.NewCenter(widget.NewRichTextFromMarkdown(fmt.Sprintf(
+			"This game's archives can only be changed with 7-Zip.\n\n"+
+				"Please download 7-Zip from [%s](%s) and install it, on Linux it can also be installed with the package manager.\n\n"+
+				"Make sure to include it on the system path when installing.\n\n"+
+				"Restart Moogle Mod Manager once 7-zip is installed.", url, url),
+		)),
+		ui.ActiveWindow())
+	d.SetOnClosed(func() {
+		wg.Done()
+	})
+	d.Show()
+	wg.Wait()
+	return mods.Cancel, nil
+}
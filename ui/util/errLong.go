@@ -1,6 +1,9 @@
 package util
 
 import (
+	"fmt"
+	"os"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -10,6 +13,11 @@ import (
 )
 
 func ShowErrorLong(err error) {
+	if ui.Window == nil {
+		// Running without a window, e.g. from the command line
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return
+	}
 	var text = widget.NewRichTextWithText(err.Error())
 	text.Wrapping = fyne.TextWrapBreak
 
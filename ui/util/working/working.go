@@ -1,24 +1,89 @@
 package working
 
 import (
+	"fmt"
+	"sync"
+
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
 	"github.com/kiamev/moogle-mod-manager/ui/state/ui"
+	"github.com/kiamev/moogle-mod-manager/util"
 )
 
-var workingDialog dialog.Dialog
+var (
+	workingDialog dialog.Dialog
+	label         *widget.Label
+	progressBar   *widget.ProgressBar
+	cancel        func()
+	mutex         sync.Mutex
+)
 
 func ShowDialog() {
+	mutex.Lock()
+	defer mutex.Unlock()
 	if workingDialog == nil {
 		if w := ui.ActiveWindow(); w != nil {
-			workingDialog = dialog.NewInformation("Working", "Working...", w)
+			label = widget.NewLabel("Working...")
+			progressBar = widget.NewProgressBar()
+			progressBar.Hide()
+			button := "OK"
+			if cancel != nil {
+				button = "Cancel"
+			}
+			d := dialog.NewCustom("Working", button, container.NewVBox(label, progressBar), w)
+			d.SetOnClosed(func() { closed(d) })
+			workingDialog = d
 			workingDialog.Show()
 		}
 	}
 }
 
 func HideDialog() {
-	if workingDialog != nil {
-		workingDialog.Hide()
-		workingDialog = nil
+	mutex.Lock()
+	d := workingDialog
+	workingDialog = nil
+	mutex.Unlock()
+	if d != nil {
+		d.Hide()
+	}
+}
+
+// SetCancel sets what the dialog's Cancel button stops, nil when the work cannot be cancelled.
+func SetCancel(f func()) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	cancel = f
+}
+
+// closed cancels the work when d was closed by its button rather than hidden once the work was done.
+func closed(d dialog.Dialog) {
+	mutex.Lock()
+	if workingDialog != d {
+		mutex.Unlock()
+		return
+	}
+	workingDialog = nil
+	f := cancel
+	mutex.Unlock()
+	if f != nil {
+		f()
+	}
+}
+
+// SetProgress shows how much of a download is done. It does nothing while the dialog is hidden.
+func SetProgress(name string, downloaded, total int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if workingDialog == nil {
+		return
+	}
+	if total <= 0 {
+		label.SetText(fmt.Sprintf("Downloading %s: %s", name, util.FormatSize(downloaded)))
+		progressBar.Hide()
+		return
 	}
+	label.SetText(fmt.Sprintf("Downloading %s: %s of %s", name, util.FormatSize(downloaded), util.FormatSize(total)))
+	progressBar.SetValue(float64(downloaded) / float64(total))
+	progressBar.Show()
 }
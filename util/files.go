@@ -1,10 +1,13 @@
 package util
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -26,6 +29,21 @@ func CreateFileName(s string) string {
 	return strings.Trim(base64.StdEncoding.EncodeToString([]byte(s)), "=")
 }
 
+// compressedTarExts are the double extensions of compressed tarballs, which are one extension as far as a file's
+// name is concerned
+var compressedTarExts = []string{".tar.gz", ".tar.xz", ".tar.zst", ".tar.bz2"}
+
+// TrimArchiveExt returns name without its archive extension, treating .tar.gz and the like as a single extension.
+func TrimArchiveExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range compressedTarExts {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
 func LoadFromFile(file string, i interface{}) (err error) {
 	var (
 		b   []byte
@@ -92,3 +110,61 @@ func MoveFile(from, to string) (err error) {
 	}
 	return
 }
+
+// CopyFile copies from to to and flushes to to disk.
+func CopyFile(from, to string) (err error) {
+	var in, out *os.File
+	if err = os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return
+	}
+	if in, err = os.Open(from); err != nil {
+		return
+	}
+	defer func() { _ = in.Close() }()
+	if out, err = os.Create(to); err != nil {
+		return
+	}
+	if _, err = io.Copy(out, in); err == nil {
+		err = out.Sync()
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(to)
+	}
+	return
+}
+
+// HashFile returns the hex encoded SHA-256 of file's contents.
+func HashFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	return Hash(f)
+}
+
+// Hash returns the hex encoded SHA-256 of everything read from r.
+func Hash(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FormatSize returns size as a human-readable number of bytes, such as "1.5 GB".
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
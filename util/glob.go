@@ -0,0 +1,49 @@
+package util
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether the slash separated path p matches pattern, ignoring case. A pattern without a "/" is
+// matched against p's base name, otherwise against the whole path where "**" matches any number of directories.
+func MatchGlob(pattern string, p string) bool {
+	pattern = strings.ToLower(strings.ReplaceAll(pattern, "\\", "/"))
+	p = strings.ToLower(strings.Trim(strings.ReplaceAll(p, "\\", "/"), "/"))
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(p, "/"))
+}
+
+// ValidGlob reports whether pattern can be used with MatchGlob.
+func ValidGlob(pattern string) bool {
+	for _, s := range strings.Split(strings.ReplaceAll(pattern, "\\", "/"), "/") {
+		if _, err := path.Match(s, ""); err != nil {
+			return false
+		}
+	}
+	return pattern != ""
+}
+
+func matchSegments(patterns []string, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
@@ -0,0 +1,51 @@
+package util
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.txt", "readme.txt", true},
+		{"*.txt", "docs/readme.txt", true},
+		{"*.TXT", "Docs/ReadMe.txt", true},
+		{"*.txt", "readme.md", false},
+		{"docs/*.txt", "docs/readme.txt", true},
+		{"docs/*.txt", "other/docs/readme.txt", false},
+		{"**/*.txt", "readme.txt", true},
+		{"**/*.txt", "a/b/readme.txt", true},
+		{"docs/**", "docs/a/b.png", true},
+		{"docs/**", "docs", true},
+		{"docs/**/b.png", "docs/b.png", true},
+		{"docs/**/b.png", "docs/a/c/b.png", true},
+		{"docs/**/b.png", "other/a/b.png", false},
+		{"docs\\*.txt", "docs\\readme.txt", true},
+		{"/docs/*.txt", "/docs/readme.txt", true},
+		{"file[0-9].dat", "dir/file3.dat", true},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"*.txt", true},
+		{"docs/**/*.png", true},
+		{"file[0-9].dat", true},
+		{"file[0-9.dat", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidGlob(tt.pattern); got != tt.want {
+			t.Errorf("ValidGlob(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind is how a version string was understood.
+type Kind byte
+
+const (
+	// FreeForm versions, such as "Final" or "Remastered", can only be checked for equality
+	FreeForm Kind = iota
+	// Numeric versions are dot separated numbers with an optional pre-release, such as semver's "1.2.3-beta.1"
+	Numeric
+	// Date versions, such as "2022.10.31" or "20221031". Dotted dates also compare with numeric versions, the ones
+	// without separators only with other dates
+	Date
+)
+
+type Version struct {
+	raw     string
+	kind    Kind
+	numbers []uint64
+	// pre holds the pre-release identifiers, a version with them comes before the same version without
+	pre []string
+	// post is a letter appended directly to the numbers, as in Nexus's "1.2a", which comes after "1.2"
+	post string
+	// compact is set for dates written without separators, such as "20221031"
+	compact bool
+}
+
+// ErrIncomparable is returned when a date without separators is compared to a numeric version or a free-form version
+// to a different one.
+var ErrIncomparable = errors.New("versions cannot be compared")
+
+var (
+	numericPrefix = regexp.MustCompile(`^(\d+(?:\.\d+)*)(.*)$`)
+	datePattern   = regexp.MustCompile(`^(\d{4})[-_/](\d{1,2})[-_/](\d{1,2})$`)
+	separators    = func(r rune) bool { return r == '.' || r == '-' || r == '_' || r == '+' || r == ' ' }
+	// preReleaseRanks orders well known pre-release names, other names sort alphabetically after them
+	preReleaseRanks = map[string]int{
+		"dev": 1, "snapshot": 1, "nightly": 1,
+		"alpha": 2, "a": 2,
+		"beta": 3, "b": 3,
+		"pre": 4, "preview": 4,
+		"rc": 5,
+	}
+)
+
+// Parse understands semver with or without a "v" prefix, pre-release tags, date based versions and Nexus style
+// versions. Text after them, as in "1.2.3 (Final)", is build metadata. Anything else is kept as a FreeForm version.
+func Parse(s string) Version {
+	v := Version{raw: s}
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, p := range []string{"version", "ver", "v"} {
+		if t := strings.TrimPrefix(s, p); t != s && t != "" && (t[0] >= '0' && t[0] <= '9' || t[0] == ' ' || t[0] == '.') {
+			s = strings.TrimLeft(t, " .")
+			break
+		}
+	}
+	if i := strings.Index(s, "+"); i != -1 {
+		// Build metadata does not take part in ordering
+		s = s[:i]
+	}
+
+	if d := datePattern.FindStringSubmatch(s); d != nil {
+		s = d[1] + "." + d[2] + "." + d[3]
+	}
+
+	m := numericPrefix.FindStringSubmatch(s)
+	if m == nil {
+		return v
+	}
+	for _, n := range strings.Split(m[1], ".") {
+		u, err := strconv.ParseUint(n, 10, 64)
+		if err != nil {
+			return Version{raw: v.raw}
+		}
+		v.numbers = append(v.numbers, u)
+	}
+	v.kind = Numeric
+	if isDate(m[1], v.numbers) {
+		v.kind = Date
+		if n := v.numbers[0]; len(v.numbers) == 1 {
+			v.numbers = []uint64{n / 10000, n / 100 % 100, n % 100}
+			v.compact = true
+		}
+	}
+
+	rest := cutTrailingText(m[2])
+	if len(rest) == 1 && rest[0] >= 'a' && rest[0] <= 'z' {
+		v.post = rest
+	} else if rest != "" {
+		for _, id := range strings.FieldsFunc(rest, separators) {
+			v.pre = append(v.pre, splitLettersAndDigits(id)...)
+		}
+		if len(v.pre) == 0 {
+			return Version{raw: v.raw}
+		}
+	}
+	return v
+}
+
+// cutTrailingText removes free text such as " (Final)" or " Remastered" from what follows a version's numbers. Like
+// build metadata it does not take part in ordering. Text after a space is only kept when it starts with a pre-release
+// name, as in "2.0 RC2".
+func cutTrailingText(rest string) string {
+	i := strings.IndexAny(rest, " ([")
+	if i == -1 {
+		return rest
+	}
+	if w := strings.Fields(rest); i == 0 && len(w) > 0 {
+		if _, found := preReleaseRanks[splitLettersAndDigits(w[0])[0]]; found {
+			return " " + w[0]
+		}
+	}
+	return rest[:i]
+}
+
+func (v Version) String() string {
+	return v.raw
+}
+
+func (v Version) Kind() Kind {
+	return v.kind
+}
+
+func (v Version) IsPreRelease() bool {
+	return len(v.pre) > 0
+}
+
+// Compare returns -1, 0 or 1 when v is older, the same or newer than o. ErrIncomparable is returned when there
+// is no meaningful order between them.
+func (v Version) Compare(o Version) (int, error) {
+	if v.kind == FreeForm || o.kind == FreeForm {
+		if strings.EqualFold(strings.TrimSpace(v.raw), strings.TrimSpace(o.raw)) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: %s and %s", ErrIncomparable, v.raw, o.raw)
+	}
+	if v.kind != o.kind && (v.compact || o.compact) {
+		// "2023.1.0" only looks unlike the date "2023.1.1" and orders by its numbers, "20230101" does not
+		return 0, fmt.Errorf("%w: %s and %s", ErrIncomparable, v.raw, o.raw)
+	}
+	for i := 0; i < len(v.numbers) || i < len(o.numbers); i++ {
+		var a, b uint64
+		if i < len(v.numbers) {
+			a = v.numbers[i]
+		}
+		if i < len(o.numbers) {
+			b = o.numbers[i]
+		}
+		if a != b {
+			return cmp(a < b), nil
+		}
+	}
+	if c := strings.Compare(v.post, o.post); c != 0 {
+		return c, nil
+	}
+	return comparePreRelease(v.pre, o.pre), nil
+}
+
+// Compare parses and compares a and b.
+func Compare(a, b string) (int, error) {
+	return Parse(a).Compare(Parse(b))
+}
+
+// IsNewer reports whether candidate is newer than current.
+func IsNewer(candidate, current string) (bool, error) {
+	c, err := Compare(candidate, current)
+	return c > 0, err
+}
+
+// Equal reports whether a and b name the same version, such as "v1.2" and "1.2.0".
+func Equal(a, b string) bool {
+	c, err := Compare(a, b)
+	return err == nil && c == 0
+}
+
+func comparePreRelease(a, b []string) int {
+	// A release comes after its pre-releases
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	if len(a) == len(b) {
+		return 0
+	}
+	return cmp(len(a) < len(b))
+}
+
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an == bn {
+			return 0
+		}
+		return cmp(an < bn)
+	case aErr == nil:
+		// Numeric identifiers have lower precedence than names
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	ar, br := preReleaseRanks[a], preReleaseRanks[b]
+	if ar != br {
+		if ar == 0 {
+			return 1
+		}
+		if br == 0 {
+			return -1
+		}
+		return cmp(ar < br)
+	}
+	return strings.Compare(a, b)
+}
+
+// cmp is -1 when less is true and 1 otherwise.
+func cmp(less bool) int {
+	if less {
+		return -1
+	}
+	return 1
+}
+
+func isDate(s string, numbers []uint64) bool {
+	if len(numbers) == 1 && len(s) == 8 {
+		y, m, d := numbers[0]/10000, numbers[0]/100%100, numbers[0]%100
+		return y >= 1900 && y < 3000 && m >= 1 && m <= 12 && d >= 1 && d <= 31
+	}
+	if len(numbers) == 3 && len(strings.Split(s, ".")[0]) == 4 {
+		return numbers[0] >= 1900 && numbers[0] < 3000 && numbers[1] >= 1 && numbers[1] <= 12 && numbers[2] >= 1 && numbers[2] <= 31
+	}
+	return false
+}
+
+// splitLettersAndDigits turns "beta2" into "beta" and "2" so it orders before "beta10".
+func splitLettersAndDigits(s string) (ids []string) {
+	start := 0
+	for i := 1; i < len(s); i++ {
+		if isDigit(s[i]) != isDigit(s[i-1]) {
+			ids = append(ids, s[start:i])
+			start = i
+		}
+	}
+	return append(ids, s[start:])
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,93 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind Kind
+		pre  bool
+	}{
+		{"1.2.3", Numeric, false},
+		{"v1.2", Numeric, false},
+		{"Version 2.0", Numeric, false},
+		{"1.2.3-beta.1", Numeric, true},
+		{"1.2.3+build.5", Numeric, false},
+		{"1.2a", Numeric, false},
+		{"2.0 RC2", Numeric, true},
+		{"1.2.3 (Final)", Numeric, false},
+		{"1.2.3(Final)", Numeric, false},
+		{"1.2 Remastered", Numeric, false},
+		{"1.2.3-beta (Final)", Numeric, true},
+		{"2.0 RC2 [Steam]", Numeric, true},
+		{"2022.10.31", Date, false},
+		{"2022-10-31", Date, false},
+		{"20221031", Date, false},
+		{"2023.1.0", Numeric, false},
+		{"2023.13.1", Numeric, false},
+		{"Final", FreeForm, false},
+		{"", FreeForm, false},
+	}
+	for _, tt := range tests {
+		v := Parse(tt.in)
+		if v.Kind() != tt.kind {
+			t.Errorf("Parse(%q).Kind() = %v, want %v", tt.in, v.Kind(), tt.kind)
+		}
+		if v.IsPreRelease() != tt.pre {
+			t.Errorf("Parse(%q).IsPreRelease() = %v, want %v", tt.in, v.IsPreRelease(), tt.pre)
+		}
+		if v.String() != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, v.String())
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b         string
+		want         int
+		incomparable bool
+	}{
+		{"1.2.3", "1.2.3", 0, false},
+		{"v1.2", "1.2.0", 0, false},
+		{"1.10", "1.9", 1, false},
+		{"1.2.3-beta.1", "1.2.3", -1, false},
+		{"1.2.3-alpha", "1.2.3-beta", -1, false},
+		{"1.2.3-beta2", "1.2.3-beta10", -1, false},
+		{"2.0-rc2", "2.0 RC2", 0, false},
+		{"1.2.3-beta", "1.2.3-beta.1", -1, false},
+		{"1.2.3-rc.1", "1.2.3-custom", -1, false},
+		{"1.2a", "1.2", 1, false},
+		{"1.2.3+build.5", "1.2.3", 0, false},
+		{"1.2.3 (Final)", "1.2.3", 0, false},
+		{"1.2.3 (Final)", "1.2.4", -1, false},
+		{"2.0 RC2 (Final)", "2.0-rc2", 0, false},
+		{"1.2a (Final)", "1.2", 1, false},
+		{"2022.10.31", "2022-11-01", -1, false},
+		{"20221031", "2022.10.31", 0, false},
+		{"2023.1.1", "2023.1.0", 1, false},
+		{"2023.1.0", "2023.1.1", -1, false},
+		{"2023.1.1", "1.2", 1, false},
+		{"20230101", "1.2", 0, true},
+		{"Final", "final", 0, false},
+		{"Final", "Remastered", 0, true},
+		{"Final", "1.0", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if tt.incomparable {
+			if !errors.Is(err, ErrIncomparable) {
+				t.Errorf("Compare(%q, %q) error = %v, want ErrIncomparable", tt.a, tt.b, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Compare(%q, %q) error = %v", tt.a, tt.b, err)
+		} else if got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}